@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/darimuri/go-lib/cmdutil"
+	"github.com/darimuri/go-lib/credential"
+)
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label"); err != nil {
+		return err
+	}
+
+	list, err := credential.List(*lbl)
+	if err != nil {
+		return err
+	}
+
+	urls := make([]string, 0, len(list))
+	for url := range list {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		fmt.Printf("%s\t%s\n", url, list[url])
+	}
+
+	return nil
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	url := fs.String("url", "", "credential url")
+	showPassword := fs.Bool("show-password", false, "print password too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label", "url"); err != nil {
+		return err
+	}
+
+	user, secret, err := credential.Get(*lbl, *url)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(user)
+	if *showPassword {
+		fmt.Println(secret)
+	}
+
+	return nil
+}
+
+func runSet(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	url := fs.String("url", "", "credential url")
+	user := fs.String("user", "", "username, prompted if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label", "url"); err != nil {
+		return err
+	}
+
+	var err error
+	if *user == "" {
+		if *user, err = cmdutil.PromptInputValidated("ID", cmdutil.Required); err != nil {
+			return err
+		}
+	}
+
+	secret, err := cmdutil.PromptNewPassword("Password")
+	if err != nil {
+		return err
+	}
+
+	return credential.Set(*lbl, *url, *user, secret)
+}
+
+func runDelete(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	url := fs.String("url", "", "credential url")
+	yes := fs.Bool("y", false, "delete without confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label", "url"); err != nil {
+		return err
+	}
+
+	if !*yes {
+		confirmed, err := cmdutil.PromptConfirm(fmt.Sprintf("Delete %s of %s", *url, *lbl))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	return credential.Del(*lbl, *url)
+}
+
+func runRename(args []string) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	url := fs.String("url", "", "credential url")
+	toLbl := fs.String("to-label", "", "new label, same label if empty")
+	toURL := fs.String("to-url", "", "new url, same url if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label", "url"); err != nil {
+		return err
+	}
+
+	if *toLbl == "" {
+		*toLbl = *lbl
+	}
+	if *toURL == "" {
+		*toURL = *url
+	}
+
+	return credential.Rename(*lbl, *url, *toLbl, *toURL)
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var lbls labels
+	fs.Var(&lbls, "label", "credential label, repeat for more labels")
+	output := fs.String("o", "-", "output file, - for stdout")
+	encrypt := fs.Bool("encrypt", false, "encrypt with a passphrase")
+	passphraseEnv := fs.String("passphrase-env", "", "environment variable having passphrase instead of prompting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label"); err != nil {
+		return err
+	}
+
+	entries, err := credential.Entries(lbls...)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, errCreate := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if errCreate != nil {
+			return errCreate
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *encrypt {
+		passphrase, errPassphrase := passphrase(*passphraseEnv, true)
+		if errPassphrase != nil {
+			return errPassphrase
+		}
+
+		return credential.WriteArchive(w, passphrase, entries)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(entries)
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := fs.String("i", "-", "input file written by export, - for stdin")
+	conflict := fs.String("conflict", "skip", "what to do with existing credentials: skip, overwrite or keep-both")
+	passphraseEnv := fs.String("passphrase-env", "", "environment variable having passphrase instead of prompting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	policy, err := credential.ParseConflictPolicy(*conflict)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, errOpen := os.Open(*input)
+		if errOpen != nil {
+			return errOpen
+		}
+		defer f.Close()
+		r = f
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var entries []credential.Entry
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		passphrase, errPassphrase := passphrase(*passphraseEnv, false)
+		if errPassphrase != nil {
+			return errPassphrase
+		}
+
+		if entries, err = credential.ReadArchive(bytes.NewReader(b), passphrase); err != nil {
+			return err
+		}
+	} else if err = json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	result, err := credential.ImportEntries(entries, policy)
+	if result != nil {
+		printEntries("imported", result.Imported)
+		printEntries("unchanged", result.Unchanged)
+		printEntries("skipped existing", result.Skipped)
+		printEntries("overwritten", result.Overwritten)
+		printEntries("kept both as", result.Kept)
+	}
+
+	return err
+}
+
+func printEntries(message string, entries []credential.Entry) {
+	for _, e := range entries {
+		fmt.Println(message, e.Label, e.URL)
+	}
+}
+
+func passphrase(env string, confirm bool) (string, error) {
+	if env != "" {
+		if p := os.Getenv(env); p != "" {
+			return p, nil
+		}
+		return "", fmt.Errorf("environment variable %s is empty", env)
+	}
+
+	if confirm {
+		return cmdutil.PromptNewPassword("Passphrase")
+	}
+
+	return cmdutil.PromptPassword("Passphrase")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+
+	"github.com/darimuri/go-lib/credential"
+	"github.com/darimuri/go-lib/rodtemplate"
+)
+
+func runTestLogin(args []string) error {
+	fs := flag.NewFlagSet("test-login", flag.ContinueOnError)
+	lbl := fs.String("label", "", "credential label")
+	url := fs.String("url", "", "credential url")
+	h := rodtemplate.LoginHandler{}
+	fs.StringVar(&h.LoginGateURL, "gate", "", "url to open first")
+	fs.StringVar(&h.LoginURL, "login-url", "", "url of login page, login-link is clicked if empty")
+	fs.StringVar(&h.LoginLinkSelector, "login-link", "", "selector of link to login page")
+	fs.StringVar(&h.LoginInputSelector, "id-input", "", "selector of id input")
+	fs.StringVar(&h.PasswordInputSelector, "password-input", "", "selector of password input")
+	fs.StringVar(&h.LoginSuccessSelector, "success", "", "selector found after login")
+	headless := fs.Bool("headless", true, "run browser without window")
+	verbose := fs.Bool("v", false, "log login steps")
+	deleteRejected := fs.Bool("delete-rejected", false, "delete the stored credential if login is rejected")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "label", "url", "gate", "id-input", "password-input", "success"); err != nil {
+		return err
+	}
+
+	var err error
+	if h.ID, h.Password, err = credential.Get(*lbl, *url); err != nil {
+		return err
+	}
+
+	controlURL, err := launcher.New().Headless(*headless).Launch()
+	if err != nil {
+		return err
+	}
+
+	b := rod.New().ControlURL(controlURL)
+	if err = b.Connect(); err != nil {
+		return err
+	}
+	defer b.Close()
+
+	bt := rodtemplate.NewBrowserTemplate(b)
+	if *verbose {
+		bt.Logger = rodtemplate.NewStdLogger(nil, rodtemplate.LevelDebug)
+	}
+
+	pt, err := bt.Login(h)
+	if errors.Is(err, credential.ErrRejected) && *deleteRejected {
+		if errDel := credential.Del(*lbl, *url); errDel != nil {
+			return errDel
+		}
+		fmt.Println("deleted rejected credential", *lbl, *url)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("logged in", pt.URL())
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Command credctl manages credentials stored by cmdutil.GetCredential.
+//
+//	credctl list -label naver
+//	credctl get -label naver -url https://nid.naver.com
+//	credctl set -label naver -url https://nid.naver.com -user me
+//	credctl delete -label naver -url https://nid.naver.com
+//	credctl rename -label naver -url https://nid.naver.com -to-url https://naver.com
+//	credctl export -label naver -label daum -encrypt -o creds.json
+//	credctl import -i creds.json -conflict keep-both
+//	credctl test-login -label naver -url https://nid.naver.com -gate https://naver.com ...
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"list", "list urls and usernames under a label", runList},
+	{"get", "print username and optionally password", runGet},
+	{"set", "store username and password prompting what's missing", runSet},
+	{"delete", "delete a credential", runDelete},
+	{"rename", "move a credential to another label or url", runRename},
+	{"export", "write credentials under labels as json, optionally encrypted", runExport},
+	{"import", "store credentials from json written by export", runImport},
+	{"test-login", "log in with a stored credential in a browser", runTestLogin},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+
+		if err := c.run(os.Args[2:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, "credctl", name+":", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: credctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run credctl <command> -h for flags of a command")
+}
+
+// labels is a flag which can be repeated
+type labels []string
+
+func (l *labels) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *labels) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func required(fs *flag.FlagSet, names ...string) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	missing := make([]string, 0)
+	for _, name := range names {
+		if !set[name] {
+			missing = append(missing, "-"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%s is required", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
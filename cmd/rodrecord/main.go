@@ -0,0 +1,98 @@
+// Command rodrecord opens a browser window, records clicks, inputs and navigations
+// until enter is pressed in the terminal and writes them as a go func using rodtemplate.
+//
+//	rodrecord -url https://example.com -func Search -o search.go
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/darimuri/go-lib/rodtemplate"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "rodrecord:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("rodrecord", flag.ContinueOnError)
+	url := fs.String("url", "", "url to open first")
+	out := fs.String("o", "", "file to write, stdout if empty")
+	opt := rodtemplate.GenerateOption{}
+	fs.StringVar(&opt.Package, "package", "main", "package of generated file")
+	fs.StringVar(&opt.Func, "func", "Scenario", "name of generated func")
+	fs.DurationVar(&opt.Timeout, "timeout", 10*time.Second, "wait for elements before clicks")
+	verbose := fs.Bool("v", false, "log recorded steps")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	controlURL, err := launcher.New().Headless(false).Launch()
+	if err != nil {
+		return err
+	}
+
+	b := rod.New().ControlURL(controlURL)
+	if err = b.Connect(); err != nil {
+		return err
+	}
+	defer b.Close()
+
+	bt := rodtemplate.NewBrowserTemplate(b)
+	if *verbose {
+		bt.Logger = rodtemplate.NewStdLogger(nil, rodtemplate.LevelDebug)
+	}
+
+	page, err := b.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return err
+	}
+
+	pt := bt.Page(page)
+
+	rec, err := pt.Record()
+	if err != nil {
+		return err
+	}
+
+	if *url != "" {
+		if err = pt.Navigate(*url); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "recording, press enter here to stop")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if err = rec.Stop(); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return rec.GenerateGo(w, opt)
+}
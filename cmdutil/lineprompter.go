@@ -0,0 +1,78 @@
+package cmdutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+var _ Prompter = (*LinePrompter)(nil)
+var _ Notifier = (*LinePrompter)(nil)
+
+// LinePrompter reads a line per prompt without terminal control sequences,
+// for dumb terminals, windows consoles and piped input.
+// Password is echoed as typed since the terminal is not put into raw mode.
+type LinePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	mu sync.Mutex
+}
+
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+// NewStdioPrompter returns a LinePrompter on os.Stdin and os.Stdout
+func NewStdioPrompter() *LinePrompter {
+	return NewLinePrompter(os.Stdin, os.Stdout)
+}
+
+func (l *LinePrompter) readLine(label string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := fmt.Fprintf(l.out, "%s: ", label); err != nil {
+		return "", err
+	}
+
+	line, err := l.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (l *LinePrompter) Input(label string) (string, error) {
+	return l.readLine(label)
+}
+
+func (l *LinePrompter) Password(label string) (string, error) {
+	return l.readLine(label)
+}
+
+func (l *LinePrompter) Confirm(label string) (bool, error) {
+	answer, err := l.readLine(label + " [y/N]")
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
+
+func (l *LinePrompter) Notify(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = fmt.Fprintln(l.out, message)
+}
@@ -0,0 +1,298 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+)
+
+// Selector is implemented by a Prompter able to pick one of items
+type Selector interface {
+	Select(label string, items []string) (int, error)
+}
+
+// MultiSelector is implemented by a Prompter able to pick some of items
+type MultiSelector interface {
+	MultiSelect(label string, items []string) ([]int, error)
+}
+
+// PromptSelect asks to pick one of items and returns its index
+func PromptSelect(label string, items []string) (int, error) {
+	return PromptSelectWith(prompter, label, items)
+}
+
+// PromptSelectWith asks p to pick one of items, by number if p is not a Selector
+func PromptSelectWith(p Prompter, label string, items []string) (int, error) {
+	if len(items) == 0 {
+		return -1, fmt.Errorf("no item to select for %s", label)
+	}
+
+	if s, ok := p.(Selector); ok {
+		return s.Select(label, items)
+	}
+
+	notifyItems(p, items)
+
+	answer, err := PromptInputWith(p, label, PromptOption{Validate: IntRange(1, len(items))})
+	if err != nil {
+		return -1, err
+	}
+
+	idx, _ := strconv.Atoi(answer)
+
+	return idx - 1, nil
+}
+
+// PromptMultiSelect asks to pick some of items and returns their indexes in order
+func PromptMultiSelect(label string, items []string) ([]int, error) {
+	return PromptMultiSelectWith(prompter, label, items)
+}
+
+// PromptMultiSelectWith asks p to pick some of items, by comma separated numbers if p is not a MultiSelector
+func PromptMultiSelectWith(p Prompter, label string, items []string) ([]int, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no item to select for %s", label)
+	}
+
+	if s, ok := p.(MultiSelector); ok {
+		return s.MultiSelect(label, items)
+	}
+
+	notifyItems(p, items)
+
+	var selected []int
+	_, err := PromptInputWith(p, label+" (comma separated)", PromptOption{Validate: func(answer string) error {
+		var errParse error
+		selected, errParse = parseNumbers(answer, len(items))
+		return errParse
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+// PromptInt asks a number between min and max inclusive
+func PromptInt(label string, min, max int) (int, error) {
+	return PromptIntWith(prompter, label, min, max, PromptOption{})
+}
+
+// PromptIntWith asks p a number between min and max inclusive, opt.Validate runs after the range check
+func PromptIntWith(p Prompter, label string, min, max int, opt PromptOption) (int, error) {
+	validate := IntRange(min, max)
+	if opt.Validate != nil {
+		validate = All(validate, opt.Validate)
+	}
+	opt.Validate = validate
+
+	answer, err := PromptInputWith(p, fmt.Sprintf("%s (%d-%d)", label, min, max), opt)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(answer)
+}
+
+// PromptInputDefault asks an input returning def for an empty answer
+func PromptInputDefault(label, def string) (string, error) {
+	return PromptInputWith(prompter, label, PromptOption{Default: def})
+}
+
+// PromptInputValidated asks an input until validators accept it
+func PromptInputValidated(label string, validators ...Validator) (string, error) {
+	return PromptInputWith(prompter, label, PromptOption{Validate: All(validators...)})
+}
+
+// ErrPasswordMismatch is returned when the password is not confirmed by retyping it
+var ErrPasswordMismatch = errors.New("passwords do not match")
+
+// PromptNewPassword asks a password twice
+func PromptNewPassword(label string) (string, error) {
+	return PromptNewPasswordWith(prompter, label, PromptOption{Validate: Required})
+}
+
+// PromptNewPasswordWith asks p a password validated by opt and asks again to confirm it
+func PromptNewPasswordWith(p Prompter, label string, opt PromptOption) (string, error) {
+	retries := opt.Retries
+	if retries == 0 {
+		retries = 2
+	}
+
+	for i := 0; i <= retries; i++ {
+		pass, err := PromptPasswordWith(p, label, opt)
+		if err != nil {
+			return "", err
+		}
+
+		confirm, err := p.Password("Confirm " + label)
+		if err != nil {
+			return "", err
+		}
+
+		if pass == strings.TrimSpace(confirm) {
+			return pass, nil
+		}
+
+		if n, ok := p.(Notifier); ok {
+			n.Notify(ErrPasswordMismatch.Error())
+		}
+	}
+
+	return "", ErrPasswordMismatch
+}
+
+func notifyItems(p Prompter, items []string) {
+	n, ok := p.(Notifier)
+	if !ok {
+		return
+	}
+
+	for idx, item := range items {
+		n.Notify(fmt.Sprintf("%d) %s", idx+1, item))
+	}
+}
+
+// parseNumbers parses comma separated 1-based numbers into 0-based indexes
+func parseNumbers(answer string, count int) ([]int, error) {
+	selected := make([]int, 0)
+	seen := map[int]bool{}
+
+	for _, field := range strings.Split(answer, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		num, err := strconv.Atoi(field)
+		if err != nil || num < 1 || count < num {
+			return nil, fmt.Errorf("%s is not between 1 and %d", field, count)
+		}
+
+		if !seen[num] {
+			seen[num] = true
+			selected = append(selected, num-1)
+		}
+	}
+
+	return selected, nil
+}
+
+var _ Selector = PromptuiPrompter{}
+var _ MultiSelector = PromptuiPrompter{}
+
+func (PromptuiPrompter) Select(label string, items []string) (int, error) {
+	s := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+
+	idx, _, err := s.Run()
+	if err != nil {
+		return -1, canceled(label, err)
+	}
+
+	return idx, nil
+}
+
+// MultiSelect toggles an item on each selection until Done is selected
+func (PromptuiPrompter) MultiSelect(label string, items []string) ([]int, error) {
+	const done = "Done"
+
+	checked := make([]bool, len(items))
+	cursor := 0
+
+	for {
+		options := make([]string, 0, len(items)+1)
+		for idx, item := range items {
+			mark := "[ ]"
+			if checked[idx] {
+				mark = "[x]"
+			}
+			options = append(options, fmt.Sprintf("%s %s", mark, item))
+		}
+		options = append(options, done)
+
+		s := promptui.Select{
+			Label: label,
+			Items: options,
+			Size:  len(options),
+		}
+
+		idx, _, err := s.RunCursorAt(cursor, 0)
+		if err != nil {
+			return nil, canceled(label, err)
+		}
+
+		if idx == len(items) {
+			break
+		}
+
+		checked[idx] = !checked[idx]
+		cursor = idx
+	}
+
+	selected := make([]int, 0)
+	for idx, c := range checked {
+		if c {
+			selected = append(selected, idx)
+		}
+	}
+
+	return selected, nil
+}
+
+var _ Selector = (*ScriptedPrompter)(nil)
+var _ MultiSelector = (*ScriptedPrompter)(nil)
+
+// Select answers with the index of the item equal to the answer, or 1-based number
+func (s *ScriptedPrompter) Select(label string, items []string) (int, error) {
+	answer, err := s.next(label)
+	if err != nil {
+		return -1, err
+	}
+
+	return scriptedIndex(answer, items)
+}
+
+// MultiSelect answers with indexes of comma separated items or 1-based numbers
+func (s *ScriptedPrompter) MultiSelect(label string, items []string) ([]int, error) {
+	answer, err := s.next(label)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]int, 0)
+	for _, field := range strings.Split(answer, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+
+		idx, errIdx := scriptedIndex(field, items)
+		if errIdx != nil {
+			return nil, errIdx
+		}
+		selected = append(selected, idx)
+	}
+
+	return selected, nil
+}
+
+func scriptedIndex(answer string, items []string) (int, error) {
+	answer = strings.TrimSpace(answer)
+
+	for idx, item := range items {
+		if item == answer {
+			return idx, nil
+		}
+	}
+
+	if num, err := strconv.Atoi(answer); err == nil && 1 <= num && num <= len(items) {
+		return num - 1, nil
+	}
+
+	return -1, fmt.Errorf("%s is not one of %v", answer, items)
+}
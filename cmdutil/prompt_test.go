@@ -0,0 +1,90 @@
+package cmdutil
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPromptSelectWith(t *testing.T) {
+	items := []string{"naver", "daum", "google"}
+
+	idx, err := PromptSelectWith(NewScriptedPrompter("daum"), "Site", items)
+	if err != nil || idx != 1 {
+		t.Errorf("Expecting 1, got %d, %v", idx, err)
+	}
+
+	out := &bytes.Buffer{}
+	idx, err = PromptSelectWith(NewLinePrompter(strings.NewReader("4\n3\n"), out), "Site", items)
+	if err != nil || idx != 2 {
+		t.Errorf("Expecting 2 after an out of range answer, got %d, %v", idx, err)
+	}
+
+	if !strings.Contains(out.String(), "3) google") || !strings.Contains(out.String(), "4 is not between 1 and 3") {
+		t.Errorf("Expecting numbered items and validation message, got %q", out.String())
+	}
+}
+
+func TestPromptMultiSelectWith(t *testing.T) {
+	items := []string{"naver", "daum", "google"}
+
+	selected, err := PromptMultiSelectWith(NewScriptedPrompter("google, naver"), "Sites", items)
+	if err != nil || !reflect.DeepEqual(selected, []int{2, 0}) {
+		t.Errorf("Expecting [2 0], got %v, %v", selected, err)
+	}
+
+	selected, err = PromptMultiSelectWith(NewLinePrompter(strings.NewReader("3,1,3\n"), &bytes.Buffer{}), "Sites", items)
+	if err != nil || !reflect.DeepEqual(selected, []int{2, 0}) {
+		t.Errorf("Expecting [2 0], got %v, %v", selected, err)
+	}
+}
+
+func TestPromptIntWith(t *testing.T) {
+	p := NewScriptedPrompter("abc", "101", "100")
+
+	val, err := PromptIntWith(p, "Count", 1, 100, PromptOption{})
+	if err != nil || val != 100 {
+		t.Errorf("Expecting 100, got %d, %v", val, err)
+	}
+
+	if len(p.Notices) != 2 || p.Asked[0] != "Count (1-100)" {
+		t.Errorf("Expecting 2 notices asking Count (1-100), got %v, %v", p.Notices, p.Asked)
+	}
+}
+
+func TestPromptNewPasswordWith(t *testing.T) {
+	pass, err := PromptNewPasswordWith(NewScriptedPrompter("a", "b", "c", "c"), "Password", PromptOption{})
+	if err != nil || pass != "c" {
+		t.Errorf("Expecting c, got %s, %v", pass, err)
+	}
+
+	_, err = PromptNewPasswordWith(NewScriptedPrompter("a", "b", "c", "d"), "Password", PromptOption{Retries: 1})
+	if !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Expecting ErrPasswordMismatch, got %v", err)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	for _, c := range []struct {
+		validate Validator
+		answer   string
+		valid    bool
+	}{
+		{Email, "user@example.com", true},
+		{Email, "user", false},
+		{URL, "https://example.com/login", true},
+		{URL, "example", false},
+		{IntRange(1, 10), "10", true},
+		{IntRange(1, 10), "0", false},
+		{Regex(regexp.MustCompile(`^\d{3}$`), "3 digits"), "123", true},
+		{Regex(regexp.MustCompile(`^\d{3}$`), "3 digits"), "12", false},
+		{All(Required, Email), "", false},
+	} {
+		if err := c.validate(c.answer); (err == nil) != c.valid {
+			t.Errorf("Expecting valid %v for %s, got %v", c.valid, c.answer, err)
+		}
+	}
+}
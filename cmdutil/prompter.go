@@ -0,0 +1,223 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+)
+
+// Prompter asks the user for values
+type Prompter interface {
+	Input(label string) (string, error)
+	Password(label string) (string, error)
+	Confirm(label string) (bool, error)
+}
+
+// Notifier is implemented by a Prompter able to show a message like a validation failure
+type Notifier interface {
+	Notify(message string)
+}
+
+type PromptOption struct {
+	// Default is returned for an empty answer
+	Default string
+	// Validate rejects an answer with an error shown to the user
+	Validate Validator
+	// Retries is the number of extra asks after a rejected answer, defaults to 2
+	Retries int
+}
+
+var prompter Prompter = PromptuiPrompter{}
+
+// SetPrompter replaces the prompter used by Prompt* functions and returns a func restoring the previous one
+func SetPrompter(p Prompter) (restore func()) {
+	prev := prompter
+	prompter = p
+
+	return func() {
+		prompter = prev
+	}
+}
+
+// PromptInputWith asks p for a trimmed input applying default, validation and retries of opt
+func PromptInputWith(p Prompter, label string, opt PromptOption) (string, error) {
+	return ask(p, label, opt, p.Input)
+}
+
+// PromptPasswordWith asks p for a trimmed password applying default, validation and retries of opt
+func PromptPasswordWith(p Prompter, label string, opt PromptOption) (string, error) {
+	return ask(p, label, opt, p.Password)
+}
+
+func ask(p Prompter, label string, opt PromptOption, askFunc func(label string) (string, error)) (string, error) {
+	retries := opt.Retries
+	if retries == 0 {
+		retries = 2
+	}
+
+	askLabel := label
+	if opt.Default != "" {
+		askLabel = fmt.Sprintf("%s [%s]", label, opt.Default)
+	}
+
+	var errValidate error
+	for i := 0; i <= retries; i++ {
+		answer, err := askFunc(askLabel)
+		if err != nil {
+			return "", err
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = opt.Default
+		}
+
+		if opt.Validate == nil {
+			return answer, nil
+		}
+
+		if errValidate = opt.Validate(answer); errValidate == nil {
+			return answer, nil
+		}
+
+		if n, ok := p.(Notifier); ok {
+			n.Notify(fmt.Sprintf("%s: %s", label, errValidate.Error()))
+		}
+	}
+
+	return "", fmt.Errorf("%s is invalid: %w", label, errValidate)
+}
+
+// notify shows message with p if it is a Notifier, prints it otherwise
+func notify(p Prompter, message string) {
+	if n, ok := p.(Notifier); ok {
+		n.Notify(message)
+		return
+	}
+
+	fmt.Println(message)
+}
+
+var _ Prompter = PromptuiPrompter{}
+var _ Notifier = PromptuiPrompter{}
+
+// PromptuiPrompter prompts on terminal with promptui
+type PromptuiPrompter struct{}
+
+// ErrCanceled is returned when the user cancels a prompt with ^C or ^D
+var ErrCanceled = errors.New("prompt canceled")
+
+// canceled turns promptui errors of ^C and ^D into ErrCanceled
+func canceled(label string, err error) error {
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) {
+		return fmt.Errorf("%w: %s", ErrCanceled, label)
+	}
+
+	return err
+}
+
+func (PromptuiPrompter) Input(label string) (string, error) {
+	p := promptui.Prompt{
+		Label: label,
+	}
+
+	result, err := p.Run()
+
+	return result, canceled(label, err)
+}
+
+func (PromptuiPrompter) Password(label string) (string, error) {
+	p := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+	}
+
+	result, err := p.Run()
+
+	return result, canceled(label, err)
+}
+
+func (PromptuiPrompter) Confirm(label string) (bool, error) {
+	p := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+
+	result, err := p.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	} else if err != nil {
+		return false, canceled(label, err)
+	}
+
+	return strings.ToLower(strings.TrimSpace(result)) == "y", nil
+}
+
+func (PromptuiPrompter) Notify(message string) {
+	fmt.Println(promptui.IconBad, message)
+}
+
+// ErrNoMoreAnswers is returned by ScriptedPrompter when every answer is consumed
+var ErrNoMoreAnswers = errors.New("no more scripted answers")
+
+var _ Prompter = (*ScriptedPrompter)(nil)
+var _ Notifier = (*ScriptedPrompter)(nil)
+
+// ScriptedPrompter answers prompts in order from Answers, "y" or "yes" confirms.
+// Asked labels are recorded in Asked and notified messages in Notices.
+type ScriptedPrompter struct {
+	Answers []string
+	Asked   []string
+	Notices []string
+
+	mu sync.Mutex
+}
+
+func NewScriptedPrompter(answers ...string) *ScriptedPrompter {
+	return &ScriptedPrompter{Answers: answers}
+}
+
+func (s *ScriptedPrompter) next(label string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Asked = append(s.Asked, label)
+
+	if len(s.Answers) == 0 {
+		return "", fmt.Errorf("%w for %s", ErrNoMoreAnswers, label)
+	}
+
+	answer := s.Answers[0]
+	s.Answers = s.Answers[1:]
+
+	return answer, nil
+}
+
+func (s *ScriptedPrompter) Input(label string) (string, error) {
+	return s.next(label)
+}
+
+func (s *ScriptedPrompter) Password(label string) (string, error) {
+	return s.next(label)
+}
+
+func (s *ScriptedPrompter) Confirm(label string) (bool, error) {
+	answer, err := s.next(label)
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
+
+func (s *ScriptedPrompter) Notify(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Notices = append(s.Notices, message)
+}
@@ -0,0 +1,63 @@
+package cmdutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLinePrompter(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewLinePrompter(strings.NewReader("user\r\nsecret\nY\n"), out)
+
+	if id, err := p.Input("ID"); err != nil || id != "user" {
+		t.Errorf("Expecting user, got %s, %v", id, err)
+	}
+
+	if pass, err := p.Password("Password"); err != nil || pass != "secret" {
+		t.Errorf("Expecting secret, got %s, %v", pass, err)
+	}
+
+	if yes, err := p.Confirm("Store?"); err != nil || !yes {
+		t.Errorf("Expecting confirmed, got %v, %v", yes, err)
+	}
+
+	if _, err := p.Input("More"); err == nil {
+		t.Error("Expecting EOF error")
+	}
+
+	if out.String() != "ID: Password: Store? [y/N]: More: " {
+		t.Errorf("Expecting prompts written, got %q", out.String())
+	}
+}
+
+func TestPromptInputWith(t *testing.T) {
+	p := NewScriptedPrompter("", "  ", "value")
+
+	answer, err := PromptInputWith(p, "Name", PromptOption{Validate: Required})
+	if err != nil || answer != "value" {
+		t.Errorf("Expecting value after retries, got %s, %v", answer, err)
+	}
+
+	if len(p.Notices) != 2 {
+		t.Errorf("Expecting 2 validation notices, got %v", p.Notices)
+	}
+
+	p = NewScriptedPrompter("")
+	answer, err = PromptInputWith(p, "Name", PromptOption{Default: "default"})
+	if err != nil || answer != "default" || p.Asked[0] != "Name [default]" {
+		t.Errorf("Expecting default, got %s, %v, asked %v", answer, err, p.Asked)
+	}
+
+	p = NewScriptedPrompter("", "")
+	_, err = PromptInputWith(p, "Name", PromptOption{Validate: Required, Retries: 1})
+	if err == nil || !strings.Contains(err.Error(), "value is required") {
+		t.Errorf("Expecting validation error after retries, got %v", err)
+	}
+
+	_, err = PromptInputWith(NewScriptedPrompter(), "Name", PromptOption{})
+	if !errors.Is(err, ErrNoMoreAnswers) {
+		t.Errorf("Expecting ErrNoMoreAnswers, got %v", err)
+	}
+}
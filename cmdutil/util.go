@@ -1,53 +1,38 @@
 package cmdutil
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/docker/docker-credential-helpers/credentials"
-	"github.com/manifoldco/promptui"
-
 	"github.com/darimuri/go-lib/credential"
 )
 
 func PromptInput(prompt string) (string, error) {
-	p := promptui.Prompt{
-		Label: prompt,
-	}
-
-	result, err := p.Run()
-
-	if err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(result), nil
+	return PromptInputWith(prompter, prompt, PromptOption{})
 }
 
 func PromptPassword(prompt string) (string, error) {
-	p := promptui.Prompt{
-		Label: prompt,
-		Mask:  '*',
-	}
-
-	result, err := p.Run()
-
-	if err != nil {
-		return "", err
-	}
+	return PromptPasswordWith(prompter, prompt, PromptOption{})
+}
 
-	return strings.TrimSpace(result), nil
+func PromptConfirm(prompt string) (bool, error) {
+	return prompter.Confirm(prompt)
 }
 
+// PromptYN returns "y" if confirmed, empty string otherwise.
+// Deprecated: use PromptConfirm which doesn't discard prompt errors
 func PromptYN(prompt string) string {
-	p := promptui.Prompt{
-		Label:     prompt,
-		IsConfirm: true,
+	yes, err := PromptConfirm(prompt)
+	if err != nil {
+		fmt.Println(prompt, "is answered as no for error", err)
 	}
 
-	result, _ := p.Run()
+	if yes {
+		return "y"
+	}
 
-	return strings.TrimSpace(result)
+	return ""
 }
 
 func ResetCredential(credLabel string, credURL string) {
@@ -56,49 +41,145 @@ func ResetCredential(credLabel string, credURL string) {
 	}
 }
 
-func GetCredential(credLabel string, credURL string) (string, string) {
-	id, pass, err := credential.Get(credLabel, credURL)
-	if err != nil && false == credentials.IsErrCredentialsNotFound(err) {
-		handleCredentialError(err)
-	}
+type CredentialOption struct {
+	// Prompter asks ID, Password if not stored, the one set by SetPrompter if nil
+	Prompter Prompter
+	// Sources are looked up before the native store, like credential.Env or credential.SecretFile
+	Sources []credential.Source
+	// Verify checks ID, Password before they are stored or returned, like logging in with them.
+	// An error wrapping credential.ErrRejected deletes the stored credential and prompts again,
+	// other errors are returned as is.
+	Verify func(id, pass string) error
+	// VerifyRetries is the number of extra prompts after a rejected credential, defaults to 2
+	VerifyRetries int
+}
+
+// PromptSourceName is the source name of values asked by PromptSource
+const PromptSourceName = "prompt"
 
-	if id == "" || pass == "" {
-		fmt.Println("ID, Password is required for", credLabel, credURL)
+// PromptSource asks p for ID and Password not resolved by previous sources of a credential.Resolver
+func PromptSource(p Prompter, credLabel string, credURL string) credential.Source {
+	return credential.SourceFunc(PromptSourceName, func(partial credential.Resolved) (id string, pass string, err error) {
+		notify(p, fmt.Sprintf("ID, Password is required for %s %s", credLabel, credURL))
 
-		if id == "" {
-			id, err = PromptInput("ID")
+		if partial.ID == "" {
+			id, err = PromptInputWith(p, "ID", PromptOption{Validate: Required})
 			if err != nil {
-				panic(err)
+				return "", "", err
 			}
 		}
 
-		if pass == "" {
-			pass, err = PromptPassword("Password")
+		if partial.Secret == "" {
+			pass, err = PromptPasswordWith(p, "Password", PromptOption{Validate: Required})
 			if err != nil {
-				panic(err)
+				return "", "", err
 			}
 		}
 
-		yn := PromptYN("Store ID, Password?")
+		return id, pass, nil
+	})
+}
+
+// GetCredential is GetCredentialWithOption with default option which panics on error
+func GetCredential(credLabel string, credURL string) (string, string) {
+	id, pass, err := GetCredentialWithOption(credLabel, credURL, CredentialOption{})
+	if err != nil {
+		panic(err)
+	}
+
+	return id, pass
+}
 
-		if strings.ToLower(yn) == "y" {
-			if err := credential.Set(credLabel, credURL, id, pass); err != nil {
-				handleCredentialError(err)
+// GetCredentialWithOption returns ID, Password resolved by ResolveCredential
+func GetCredentialWithOption(credLabel string, credURL string, opt CredentialOption) (string, string, error) {
+	resolved, err := ResolveCredential(credLabel, credURL, opt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return resolved.ID, resolved.Secret, nil
+}
+
+// ResolveCredential resolves ID, Password from opt.Sources, the native store and prompts in order.
+// Prompted values are stored if confirmed after opt.Verify accepts them.
+func ResolveCredential(credLabel string, credURL string, opt CredentialOption) (*credential.Resolved, error) {
+	p := opt.Prompter
+	if p == nil {
+		p = prompter
+	}
+
+	retries := opt.VerifyRetries
+	if retries == 0 {
+		retries = 2
+	}
+
+	storeSource := credential.NativeStore(credLabel, credURL)
+
+	sources := append([]credential.Source{}, opt.Sources...)
+	sources = append(sources, storeSource, PromptSource(p, credLabel, credURL))
+
+	var resolved *credential.Resolved
+	for i := 0; ; i++ {
+		var err error
+		if resolved, err = credential.NewResolver(sources...).Resolve(); err != nil {
+			return nil, credentialError(err)
+		}
+
+		if opt.Verify == nil {
+			break
+		}
+
+		errVerify := opt.Verify(resolved.ID, resolved.Secret)
+		if errVerify == nil {
+			break
+		} else if !errors.Is(errVerify, credential.ErrRejected) {
+			return nil, errVerify
+		}
+
+		stored := resolved.IDSource == storeSource.Name() || resolved.SecretSource == storeSource.Name()
+		prompted := resolved.IDSource == PromptSourceName || resolved.SecretSource == PromptSourceName
+
+		if stored {
+			notify(p, fmt.Sprintf("stored ID, Password for %s %s is rejected and deleted: %v", credLabel, credURL, errVerify))
+			if err = credential.Del(credLabel, credURL); err != nil {
+				return nil, credentialError(err)
 			}
+		} else if prompted {
+			notify(p, fmt.Sprintf("ID, Password is rejected: %v", errVerify))
+		}
+
+		if !stored && !prompted || i >= retries {
+			return nil, fmt.Errorf("ID from %s, Password from %s: %w", resolved.IDSource, resolved.SecretSource, errVerify)
 		}
 	}
 
-	return id, pass
+	if resolved.IDSource != PromptSourceName && resolved.SecretSource != PromptSourceName {
+		return resolved, nil
+	}
+
+	yes, err := p.Confirm("Store ID, Password?")
+	if err != nil {
+		return nil, err
+	}
+
+	if yes {
+		if err = credential.Set(credLabel, credURL, resolved.ID, resolved.Secret); err != nil {
+			return nil, credentialError(err)
+		}
+	}
+
+	return resolved, nil
 }
 
-func handleCredentialError(err error) {
+// credentialError explains how to set up the native store if it's not ready
+func credentialError(err error) error {
 	errorString := strings.TrimSpace(err.Error())
 	if strings.HasSuffix(errorString, `executable file not found in $PATH:`) {
-		panic(`
+		return errors.New(`
 pass should be installed.
 Refer Download section in https://www.passwordstore.org/ to install pass.`)
 	} else if strings.HasSuffix(errorString, `Error: password store is empty. Try "pass init".`) {
-		panic(`
+		return errors.New(`
 pass is not initialized.
 Init pass on command line as follows.
 ---
@@ -114,5 +195,5 @@ sub   rsa4096 2020-12-25 [E]
 pass init gpg-id
 ---`)
 	}
-	panic(err)
+	return err
 }
@@ -0,0 +1,175 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+func TestGetCredentialStored(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	if err := credential.Set("label", "url", "user", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewScriptedPrompter()
+	defer SetPrompter(p)()
+
+	id, pass := GetCredential("label", "url")
+	if id != "user" || pass != "password" {
+		t.Errorf("Expecting stored credential, got %s, %s", id, pass)
+	}
+
+	if len(p.Asked) != 0 {
+		t.Errorf("Expecting no prompt, got %v", p.Asked)
+	}
+}
+
+func TestGetCredentialPrompt(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	p := NewScriptedPrompter(" user ", "password", "y")
+	defer SetPrompter(p)()
+
+	id, pass := GetCredential("label", "url")
+	if id != "user" || pass != "password" {
+		t.Errorf("Expecting prompted credential, got %s, %s", id, pass)
+	}
+
+	if len(p.Asked) != 3 {
+		t.Errorf("Expecting ID, Password and store prompts, got %v", p.Asked)
+	}
+
+	if storedID, storedPass, err := credential.Get("label", "url"); err != nil || storedID != "user" || storedPass != "password" {
+		t.Errorf("Expecting stored credential, got %s, %s, %v", storedID, storedPass, err)
+	}
+}
+
+func TestGetCredentialPromptNotStored(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+	defer SetPrompter(NewScriptedPrompter("user", "password", "n"))()
+
+	GetCredential("label", "url")
+
+	if _, _, err := credential.Get("label", "url"); err == nil {
+		t.Error("Expecting credential not stored")
+	}
+}
+
+func TestGetCredentialWithOptionPromptError(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	_, _, err := GetCredentialWithOption("label", "url", CredentialOption{Prompter: NewScriptedPrompter("user", "password")})
+	if !errors.Is(err, ErrNoMoreAnswers) {
+		t.Errorf("Expecting confirm error returned, got %v", err)
+	}
+}
+
+func TestResolveCredentialSources(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	t.Setenv("CMDUTIL_TEST_ID", "env-user")
+
+	p := NewScriptedPrompter("password", "n")
+
+	resolved, err := ResolveCredential("label", "url", CredentialOption{
+		Prompter: p,
+		Sources:  []credential.Source{credential.Env("CMDUTIL_TEST_ID", "")},
+	})
+	if err != nil {
+		t.Fatalf("Expecting resolved, got %v", err)
+	}
+
+	if resolved.ID != "env-user" || resolved.IDSource != "env:CMDUTIL_TEST_ID" {
+		t.Errorf("Expecting id from env, got %s from %s", resolved.ID, resolved.IDSource)
+	}
+
+	if resolved.Secret != "password" || resolved.SecretSource != PromptSourceName {
+		t.Errorf("Expecting password from prompt, got %s from %s", resolved.Secret, resolved.SecretSource)
+	}
+
+	if len(p.Asked) != 2 || p.Asked[0] != "Password" {
+		t.Errorf("Expecting only password and store prompts, got %v", p.Asked)
+	}
+}
+
+func rejectUnless(pass string) func(string, string) error {
+	return func(_, p string) error {
+		if p != pass {
+			return fmt.Errorf("wrong password: %w", credential.ErrRejected)
+		}
+		return nil
+	}
+}
+
+func TestResolveCredentialVerifyStored(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	if err := credential.Set("label", "url", "user", "typo"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewScriptedPrompter("user", "password", "y")
+
+	resolved, err := ResolveCredential("label", "url", CredentialOption{Prompter: p, Verify: rejectUnless("password")})
+	if err != nil {
+		t.Fatalf("Expecting resolved, got %v", err)
+	}
+
+	if resolved.Secret != "password" || resolved.SecretSource != PromptSourceName {
+		t.Errorf("Expecting password prompted again, got %+v", resolved)
+	}
+
+	if len(p.Notices) != 2 || !strings.Contains(p.Notices[0], "rejected") || !strings.Contains(p.Notices[1], "required") {
+		t.Errorf("Expecting rejected and prompt notices, got %v", p.Notices)
+	}
+
+	if _, pass, _ := credential.Get("label", "url"); pass != "password" {
+		t.Errorf("Expecting verified password stored, got %s", pass)
+	}
+}
+
+func TestResolveCredentialVerifyPrompted(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	p := NewScriptedPrompter("user", "typo", "user", "typo")
+
+	_, err := ResolveCredential("label", "url", CredentialOption{Prompter: p, Verify: rejectUnless("password"), VerifyRetries: 1})
+	if !errors.Is(err, credential.ErrRejected) {
+		t.Errorf("Expecting ErrRejected after retries, got %v", err)
+	}
+
+	if len(p.Answers) != 0 {
+		t.Errorf("Expecting store not asked, left %v", p.Answers)
+	}
+
+	if _, _, errGet := credential.Get("label", "url"); errGet == nil {
+		t.Error("Expecting rejected credential not stored")
+	}
+}
+
+func TestResolveCredentialVerifyError(t *testing.T) {
+	defer credential.SetStore(credential.NewMemoryStore())()
+
+	if err := credential.Set("label", "url", "user", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	errNetwork := errors.New("network is down")
+
+	_, err := ResolveCredential("label", "url", CredentialOption{
+		Prompter: NewScriptedPrompter(),
+		Verify:   func(string, string) error { return errNetwork },
+	})
+	if !errors.Is(err, errNetwork) {
+		t.Errorf("Expecting verify error, got %v", err)
+	}
+
+	if _, _, errGet := credential.Get("label", "url"); errGet != nil {
+		t.Errorf("Expecting stored credential kept, got %v", errGet)
+	}
+}
@@ -0,0 +1,80 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// Validator rejects an answer with an error shown to the user
+type Validator func(answer string) error
+
+// Required rejects an empty answer
+func Required(answer string) error {
+	if answer == "" {
+		return errors.New("value is required")
+	}
+
+	return nil
+}
+
+// Email rejects an answer which is not an email address
+func Email(answer string) error {
+	if !govalidator.IsEmail(answer) {
+		return fmt.Errorf("%s is not an email address", answer)
+	}
+
+	return nil
+}
+
+// URL rejects an answer which is not an absolute url
+func URL(answer string) error {
+	if !govalidator.IsRequestURL(answer) {
+		return fmt.Errorf("%s is not an url", answer)
+	}
+
+	return nil
+}
+
+// IntRange rejects an answer which is not an integer between min and max inclusive
+func IntRange(min, max int) Validator {
+	return func(answer string) error {
+		val, err := strconv.Atoi(answer)
+		if err != nil {
+			return fmt.Errorf("%s is not a number", answer)
+		}
+
+		if val < min || max < val {
+			return fmt.Errorf("%d is not between %d and %d", val, min, max)
+		}
+
+		return nil
+	}
+}
+
+// Regex rejects an answer not matching pattern, message explains the expected format
+func Regex(pattern *regexp.Regexp, message string) Validator {
+	return func(answer string) error {
+		if !pattern.MatchString(answer) {
+			return errors.New(message)
+		}
+
+		return nil
+	}
+}
+
+// All rejects an answer with the first error of validators
+func All(validators ...Validator) Validator {
+	return func(answer string) error {
+		for _, v := range validators {
+			if err := v(answer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
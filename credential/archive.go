@@ -0,0 +1,244 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	archiveVersion = 1
+	archiveKDF     = "scrypt"
+	archiveCipher  = "aes-256-gcm"
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrWrongPassphrase is returned when an archive can't be decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted archive")
+
+// archive is a passphrase encrypted json of []Entry
+type archive struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Cipher     string `json:"cipher"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ConflictPolicy decides what to do when an imported credential exists with another username or secret
+type ConflictPolicy int
+
+const (
+	// ConflictSkip keeps the existing credential
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite replaces the existing credential
+	ConflictOverwrite
+	// ConflictKeepBoth stores the imported credential under url with a " (N)" suffix
+	ConflictKeepBoth
+)
+
+// ParseConflictPolicy parses skip, overwrite or keep-both
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch s {
+	case "skip":
+		return ConflictSkip, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "keep-both":
+		return ConflictKeepBoth, nil
+	}
+
+	return ConflictSkip, fmt.Errorf("unknown conflict policy %s, one of skip, overwrite, keep-both", s)
+}
+
+// ImportResult tells what happened to each imported entry, Kept has entries stored under a new url
+type ImportResult struct {
+	Imported    []Entry
+	Unchanged   []Entry
+	Skipped     []Entry
+	Overwritten []Entry
+	Kept        []Entry
+}
+
+// ExportArchive writes every credential under labels into w encrypted with passphrase
+func ExportArchive(w io.Writer, passphrase string, labels ...string) error {
+	entries, err := Entries(labels...)
+	if err != nil {
+		return err
+	}
+
+	return WriteArchive(w, passphrase, entries)
+}
+
+// WriteArchive writes entries into w encrypted with passphrase
+func WriteArchive(w io.Writer, passphrase string, entries []Entry) error {
+	if passphrase == "" {
+		return errors.New("passphrase is required")
+	}
+
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	a := archive{
+		Version: archiveVersion,
+		KDF:     archiveKDF,
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Cipher:  archiveCipher,
+		Salt:    make([]byte, 16),
+	}
+
+	if _, err = rand.Read(a.Salt); err != nil {
+		return err
+	}
+
+	aead, err := a.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	a.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(a.Nonce); err != nil {
+		return err
+	}
+
+	a.Ciphertext = aead.Seal(nil, a.Nonce, plaintext, nil)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(a)
+}
+
+// ReadArchive decrypts entries written by WriteArchive
+func ReadArchive(r io.Reader, passphrase string) ([]Entry, error) {
+	var a archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, err
+	}
+
+	if a.Version != archiveVersion || a.KDF != archiveKDF || a.Cipher != archiveCipher {
+		return nil, fmt.Errorf("unsupported archive version %d, kdf %s, cipher %s", a.Version, a.KDF, a.Cipher)
+	}
+
+	// parameters are not taken from the archive as is, a huge N of a crafted one allocates gigabytes
+	if a.N != scryptN || a.R != scryptR || a.P != scryptP {
+		return nil, fmt.Errorf("unsupported archive scrypt parameters N=%d, r=%d, p=%d", a.N, a.R, a.P)
+	}
+
+	aead, err := a.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("unsupported archive nonce of %d bytes", len(a.Nonce))
+	}
+
+	plaintext, err := aead.Open(nil, a.Nonce, a.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var entries []Entry
+	if err = json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ImportArchive stores entries of an archive into the active store resolving conflicts with policy
+func ImportArchive(r io.Reader, passphrase string, policy ConflictPolicy) (*ImportResult, error) {
+	entries, err := ReadArchive(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImportEntries(entries, policy)
+}
+
+// ImportEntries stores entries into the active store resolving conflicts with policy.
+// An entry equal to the stored one is left unchanged.
+func ImportEntries(entries []Entry, policy ConflictPolicy) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for _, e := range entries {
+		user, secret, err := Get(e.Label, e.URL)
+		if err != nil && !credentials.IsErrCredentialsNotFound(err) {
+			return result, err
+		}
+
+		exists := err == nil && user != ""
+
+		switch {
+		case !exists:
+			err = Set(e.Label, e.URL, e.Username, e.Secret)
+			result.Imported = append(result.Imported, e)
+		case user == e.Username && secret == e.Secret:
+			result.Unchanged = append(result.Unchanged, e)
+		case policy == ConflictOverwrite:
+			err = Set(e.Label, e.URL, e.Username, e.Secret)
+			result.Overwritten = append(result.Overwritten, e)
+		case policy == ConflictKeepBoth:
+			kept := e
+			if kept.URL, err = freeURL(e.Label, e.URL); err == nil {
+				err = Set(kept.Label, kept.URL, kept.Username, kept.Secret)
+			}
+			result.Kept = append(result.Kept, kept)
+		default:
+			result.Skipped = append(result.Skipped, e)
+		}
+
+		if err != nil {
+			return result, fmt.Errorf("failed to import %s %s: %w", e.Label, e.URL, err)
+		}
+	}
+
+	return result, nil
+}
+
+// freeURL returns url with the smallest " (N)" suffix not stored under lbl
+func freeURL(lbl, url string) (string, error) {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", url, i)
+
+		_, _, err := Get(lbl, candidate)
+		if credentials.IsErrCredentialsNotFound(err) {
+			return candidate, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (a archive) aead(passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), a.Salt, a.N, a.R, a.P, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
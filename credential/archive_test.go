@@ -0,0 +1,90 @@
+package credential
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	if err := Set("label", "url", "user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := ExportArchive(buf, "passphrase", "label"); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(buf.Bytes(), []byte("secret")) {
+		t.Fatal("Expecting secret encrypted")
+	}
+
+	if _, err := ReadArchive(bytes.NewReader(buf.Bytes()), "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expecting ErrWrongPassphrase, got %v", err)
+	}
+
+	if err := Set("label", "url", "user", "changed"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ImportArchive(bytes.NewReader(buf.Bytes()), "passphrase", ConflictSkip)
+	if err != nil || len(result.Skipped) != 1 {
+		t.Fatalf("Expecting skipped, got %+v, %v", result, err)
+	}
+
+	if _, secret, _ := Get("label", "url"); secret != "changed" {
+		t.Errorf("Expecting existing secret kept, got %s", secret)
+	}
+
+	result, err = ImportArchive(bytes.NewReader(buf.Bytes()), "passphrase", ConflictKeepBoth)
+	if err != nil || len(result.Kept) != 1 || result.Kept[0].URL != "url (2)" {
+		t.Fatalf("Expecting kept as url (2), got %+v, %v", result, err)
+	}
+
+	if _, secret, _ := Get("label", "url (2)"); secret != "secret" {
+		t.Errorf("Expecting imported secret under url (2), got %s", secret)
+	}
+
+	result, err = ImportArchive(bytes.NewReader(buf.Bytes()), "passphrase", ConflictOverwrite)
+	if err != nil || len(result.Overwritten) != 1 {
+		t.Fatalf("Expecting overwritten, got %+v, %v", result, err)
+	}
+
+	if _, secret, _ := Get("label", "url"); secret != "secret" {
+		t.Errorf("Expecting overwritten secret, got %s", secret)
+	}
+
+	result, err = ImportArchive(bytes.NewReader(buf.Bytes()), "passphrase", ConflictKeepBoth)
+	if err != nil || len(result.Unchanged) != 1 {
+		t.Errorf("Expecting unchanged, got %+v, %v", result, err)
+	}
+}
+
+func TestReadArchiveUnsupported(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteArchive(buf, "passphrase", []Entry{{Label: "label", URL: "url", Username: "user", Secret: "secret"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range map[string]interface{}{"n": 1 << 30, "r": 1 << 20, "p": 64, "nonce": "AAAA"} {
+		var a map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &a); err != nil {
+			t.Fatal(err)
+		}
+		a[key] = value
+
+		crafted, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = ReadArchive(bytes.NewReader(crafted), "passphrase"); err == nil || !strings.Contains(err.Error(), "unsupported archive") {
+			t.Errorf("Expecting unsupported archive for %s, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package credential
+
+import (
+	"sort"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// Entry is a stored credential with its label
+type Entry struct {
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+}
+
+// List returns stored urls and their usernames under lbl.
+// The pass backend has no label so that it lists every url.
+func List(lbl string) (map[string]string, error) {
+	credentials.SetCredsLabel(lbl)
+	return ns.List()
+}
+
+// Entries returns every credential with secret stored under labels sorted by label and url
+func Entries(labels ...string) ([]Entry, error) {
+	entries := make([]Entry, 0)
+
+	for _, lbl := range labels {
+		list, err := List(lbl)
+		if err != nil {
+			return nil, err
+		}
+
+		for url := range list {
+			user, secret, errGet := Get(lbl, url)
+			if errGet != nil {
+				return nil, errGet
+			}
+
+			entries = append(entries, Entry{Label: lbl, URL: url, Username: user, Secret: secret})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Label != entries[j].Label {
+			return entries[i].Label < entries[j].Label
+		}
+		return entries[i].URL < entries[j].URL
+	})
+
+	return entries, nil
+}
+
+// Rename moves a credential to another label and url
+func Rename(lbl, url, toLbl, toURL string) error {
+	user, secret, err := Get(lbl, url)
+	if err != nil {
+		return err
+	}
+
+	if err = Set(toLbl, toURL, user, secret); err != nil {
+		return err
+	}
+
+	if lbl == toLbl && url == toURL {
+		return nil
+	}
+
+	return Del(lbl, url)
+}
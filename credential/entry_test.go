@@ -0,0 +1,47 @@
+package credential
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+func TestEntriesRename(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	for _, e := range []Entry{
+		{Label: "b", URL: "url2", Username: "user2", Secret: "secret2"},
+		{Label: "a", URL: "url1", Username: "user1", Secret: "secret1"},
+		{Label: "c", URL: "url3", Username: "user3", Secret: "secret3"},
+	} {
+		if err := Set(e.Label, e.URL, e.Username, e.Secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Entries("b", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Entry{
+		{Label: "a", URL: "url1", Username: "user1", Secret: "secret1"},
+		{Label: "b", URL: "url2", Username: "user2", Secret: "secret2"},
+	}
+	if !reflect.DeepEqual(entries, expect) {
+		t.Errorf("Expecting %v, got %v", expect, entries)
+	}
+
+	if err = Rename("a", "url1", "c", "url1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = Get("a", "url1"); !credentials.IsErrCredentialsNotFound(err) {
+		t.Errorf("Expecting renamed credential removed, got %v", err)
+	}
+
+	if user, _, errGet := Get("c", "url1"); errGet != nil || user != "user1" {
+		t.Errorf("Expecting renamed credential, got %s, %v", user, errGet)
+	}
+}
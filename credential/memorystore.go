@@ -0,0 +1,111 @@
+package credential
+
+import (
+	"sync"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+var _ credentials.Helper = (*MemoryStore)(nil)
+var _ credentials.Helper = (*FailingStore)(nil)
+
+// MemoryStore keeps credentials in memory.
+// Like native stores, credentials are separated by the label set with credentials.SetCredsLabel.
+type MemoryStore struct {
+	mu    sync.Mutex
+	creds map[string]map[string]credentials.Credentials
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{creds: map[string]map[string]credentials.Credentials{}}
+}
+
+func (s *MemoryStore) Add(cr *credentials.Credentials) error {
+	if cr.ServerURL == "" {
+		return credentials.NewErrCredentialsMissingServerURL()
+	}
+
+	if cr.Username == "" {
+		return credentials.NewErrCredentialsMissingUsername()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byURL, ok := s.creds[credentials.CredsLabel]
+	if !ok {
+		byURL = map[string]credentials.Credentials{}
+		s.creds[credentials.CredsLabel] = byURL
+	}
+
+	byURL[cr.ServerURL] = *cr
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(serverURL string) error {
+	if serverURL == "" {
+		return credentials.NewErrCredentialsMissingServerURL()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byURL := s.creds[credentials.CredsLabel]
+	if _, ok := byURL[serverURL]; !ok {
+		return credentials.NewErrCredentialsNotFound()
+	}
+
+	delete(byURL, serverURL)
+
+	return nil
+}
+
+func (s *MemoryStore) Get(serverURL string) (string, string, error) {
+	if serverURL == "" {
+		return "", "", credentials.NewErrCredentialsMissingServerURL()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cr, ok := s.creds[credentials.CredsLabel][serverURL]
+	if !ok {
+		return "", "", credentials.NewErrCredentialsNotFound()
+	}
+
+	return cr.Username, cr.Secret, nil
+}
+
+func (s *MemoryStore) List() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := map[string]string{}
+	for url, cr := range s.creds[credentials.CredsLabel] {
+		list[url] = cr.Username
+	}
+
+	return list, nil
+}
+
+// FailingStore returns Err from every call, useful to test handling of a broken native store
+type FailingStore struct {
+	Err error
+}
+
+func (s *FailingStore) Add(*credentials.Credentials) error {
+	return s.Err
+}
+
+func (s *FailingStore) Delete(string) error {
+	return s.Err
+}
+
+func (s *FailingStore) Get(string) (string, string, error) {
+	return "", "", s.Err
+}
+
+func (s *FailingStore) List() (map[string]string, error) {
+	return nil, s.Err
+}
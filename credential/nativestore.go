@@ -9,16 +9,38 @@ func Set(lbl, url, user, secret string) error {
 		Secret:    secret,
 	}
 
+	Secrets.Add(secret)
+
 	credentials.SetCredsLabel(lbl)
 	return ns.Add(cr)
 }
 
+// Get returns the stored username and secret, the secret is registered to Secrets until Secrets.Remove
 func Get(lbl, url string) (string, string, error) {
 	credentials.SetCredsLabel(lbl)
-	return ns.Get(url)
+
+	user, secret, err := ns.Get(url)
+	Secrets.Add(secret)
+
+	return user, secret, err
 }
 
 func Del(lbl, url string) error {
 	credentials.SetCredsLabel(lbl)
 	return ns.Delete(url)
 }
+
+// Store returns the backend used by Set, Get and Del, the native store of the OS by default
+func Store() credentials.Helper {
+	return ns
+}
+
+// SetStore replaces the backend used by Set, Get and Del and returns a func restoring the previous one
+func SetStore(h credentials.Helper) (restore func()) {
+	prev := ns
+	ns = h
+
+	return func() {
+		ns = prev
+	}
+}
@@ -1,5 +1,8 @@
 package credential
 
-import "github.com/docker/docker-credential-helpers/osxkeychain"
+import (
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/docker/docker-credential-helpers/osxkeychain"
+)
 
-var ns = osxkeychain.Osxkeychain{}
+var ns credentials.Helper = osxkeychain.Osxkeychain{}
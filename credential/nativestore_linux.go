@@ -1,5 +1,8 @@
 package credential
 
-import "github.com/docker/docker-credential-helpers/pass"
+import (
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/docker/docker-credential-helpers/pass"
+)
 
-var ns = pass.Pass{}
+var ns credentials.Helper = pass.Pass{}
@@ -1,14 +1,31 @@
 package credential
 
 import (
-	"log"
+	"os"
 	"testing"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 )
 
-// Uses 자격 증명 관리자 on windows
+// set CREDENTIAL_NATIVE_TEST=1 to run against the native store
+// which uses 자격 증명 관리자 on windows, keychain on darwin and pass on linux
+const envNativeTest = "CREDENTIAL_NATIVE_TEST"
+
 func TestSetGet(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	testSetGet(t)
+}
+
+func TestNativeSetGet(t *testing.T) {
+	if os.Getenv(envNativeTest) != "1" {
+		t.Skipf("set %s=1 to test native store", envNativeTest)
+	}
+
+	testSetGet(t)
+}
+
+func testSetGet(t *testing.T) {
 	url := "github.com/dormael/naver-shop-click"
 	lbl := "naver-shop-click"
 
@@ -27,20 +44,20 @@ func TestSetGet(t *testing.T) {
 
 	err = Set(lbl, url, "user", "password")
 	if err != nil {
-		t.Errorf("Expecting empty error, got %v", err)
+		t.Fatalf("Expecting empty error, got %v", err)
 	}
 
 	user, secret, err = Get(lbl, url)
-	if err == nil {
-		if user != "user" {
-			t.Errorf("Expecting user, got %s", user)
-		}
+	if err != nil {
+		t.Fatalf("Expecting empty error, got %v", err)
+	}
 
-		if secret != "password" {
-			t.Errorf("Expecting password, got %s", secret)
-		}
-	} else {
-		log.Println("got error:", err)
+	if user != "user" {
+		t.Errorf("Expecting user, got %s", user)
+	}
+
+	if secret != "password" {
+		t.Errorf("Expecting password, got %s", secret)
 	}
 
 	err = Del(lbl, url)
@@ -48,3 +65,23 @@ func TestSetGet(t *testing.T) {
 		t.Errorf("Expecting empty error, got %v", err)
 	}
 }
+
+func TestMemoryStoreLabel(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	if err := Set("label1", "url", "user1", "secret1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Get("label2", "url"); !credentials.IsErrCredentialsNotFound(err) {
+		t.Errorf("Expecting CredentialNotFound error for another label, got %v", err)
+	}
+
+	if user, _, err := Get("label1", "url"); err != nil || user != "user1" {
+		t.Errorf("Expecting user1, got %s, %v", user, err)
+	}
+
+	if err := Del("label2", "url"); !credentials.IsErrCredentialsNotFound(err) {
+		t.Errorf("Expecting CredentialNotFound error deleting another label, got %v", err)
+	}
+}
@@ -1,5 +1,8 @@
 package credential
 
-import "github.com/docker/docker-credential-helpers/wincred"
+import (
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/docker/docker-credential-helpers/wincred"
+)
 
-var ns = wincred.Wincred{}
+var ns credentials.Helper = wincred.Wincred{}
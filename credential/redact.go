@@ -0,0 +1,160 @@
+package credential
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces a secret masked by a Redactor
+const Redacted = "[REDACTED]"
+
+// MinSecretLength is the shortest secret a Redactor masks, shorter ones would mask common words and numbers
+const MinSecretLength = 4
+
+// Redactor masks registered secrets in text.
+// Besides the secret as is, its url query, url path and json escaped forms are masked
+// to cover form posts, urls and json artifacts.
+type Redactor struct {
+	mu sync.RWMutex
+	// secrets counts registrations so that a secret stays masked until every Add is paired with Remove
+	secrets  map[string]int
+	replacer *strings.Replacer
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{secrets: map[string]int{}}
+}
+
+// Secrets is the Redactor knowing secrets got or resolved by this package,
+// like passwords read from the store and resolved by Resolver
+var Secrets = NewRedactor()
+
+// Add registers secrets to mask, ones shorter than MinSecretLength are ignored.
+// Pair it with Remove once the secret is not used, a secret added twice is masked until removed twice.
+func (r *Redactor) Add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range secrets {
+		if len(s) < MinSecretLength {
+			continue
+		}
+		r.secrets[s]++
+	}
+
+	r.replacer = nil
+}
+
+// Remove unregisters secrets added by Add
+func (r *Redactor) Remove(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range secrets {
+		if r.secrets[s] <= 1 {
+			delete(r.secrets, s)
+			continue
+		}
+		r.secrets[s]--
+	}
+
+	r.replacer = nil
+}
+
+// Redact returns s with every registered secret replaced by Redacted
+func (r *Redactor) Redact(s string) string {
+	replacer := r.getReplacer()
+	if replacer == nil {
+		return s
+	}
+
+	return replacer.Replace(s)
+}
+
+// RedactError returns an error having redacted message of err, which is still matched by errors.Is and errors.As
+func (r *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	if redacted := r.Redact(msg); redacted != msg {
+		return &redactedError{msg: redacted, err: err}
+	}
+
+	return err
+}
+
+func (r *Redactor) getReplacer() *strings.Replacer {
+	r.mu.RLock()
+	replacer, count := r.replacer, len(r.secrets)
+	r.mu.RUnlock()
+
+	if replacer != nil || count == 0 {
+		return replacer
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replacer != nil {
+		return r.replacer
+	}
+
+	forms := map[string]struct{}{}
+	for s := range r.secrets {
+		forms[s] = struct{}{}
+		forms[url.QueryEscape(s)] = struct{}{}
+		forms[url.PathEscape(s)] = struct{}{}
+		if b, err := json.Marshal(s); err == nil {
+			forms[string(b[1:len(b)-1])] = struct{}{}
+		}
+	}
+
+	// longer first so that a secret containing another is masked as a whole
+	sorted := make([]string, 0, len(forms))
+	for f := range forms {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	oldnew := make([]string, 0, len(sorted)*2)
+	for _, f := range sorted {
+		oldnew = append(oldnew, f, Redacted)
+	}
+
+	r.replacer = strings.NewReplacer(oldnew...)
+
+	return r.replacer
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Redact masks secrets known to Secrets in s
+func Redact(s string) string {
+	return Secrets.Redact(s)
+}
+
+// RedactError masks secrets known to Secrets in the message of err
+func RedactError(err error) error {
+	return Secrets.RedactError(err)
+}
@@ -0,0 +1,104 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor()
+
+	if r.Redact("nothing registered") != "nothing registered" {
+		t.Error("Expecting text unchanged without secrets")
+	}
+
+	r.Add("p@ss word\"", "", "p@ss")
+
+	cases := map[string]string{
+		`typed p@ss word" into #password`:      `typed [REDACTED] into #password`,
+		`id=user&password=p%40ss+word%22`:      `id=user&password=[REDACTED]`,
+		`/login/p@ss%20word%22`:                `/login/[REDACTED]`,
+		`{"value":"p@ss word\""}`:              `{"value":"[REDACTED]"}`,
+		`short p@ss and the long p@ss word"`:   `short [REDACTED] and the long [REDACTED]`,
+		`nothing to redact in this p@-ss text`: `nothing to redact in this p@-ss text`,
+	}
+
+	for text, expected := range cases {
+		if got := r.Redact(text); got != expected {
+			t.Errorf("Expecting %s, got %s", expected, got)
+		}
+	}
+
+	r.Remove("p@ss word\"", "p@ss")
+	if r.Redact("p@ss") != "p@ss" {
+		t.Error("Expecting removed secret not redacted")
+	}
+}
+
+func TestRedactorShortAndCounted(t *testing.T) {
+	r := NewRedactor()
+
+	r.Add("abc")
+	if r.Redact("abc") != "abc" {
+		t.Error("Expecting secret shorter than MinSecretLength not redacted")
+	}
+
+	r.Add("password", "password")
+	r.Remove("password")
+	if r.Redact("password") != Redacted {
+		t.Error("Expecting secret added twice redacted after one remove")
+	}
+
+	r.Remove("password")
+	if r.Redact("password") != "password" {
+		t.Error("Expecting secret not redacted after every add is removed")
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	r := NewRedactor()
+	r.Add("secret")
+
+	errBase := errors.New("failed to type secret")
+	err := r.RedactError(fmt.Errorf("login: %w", errBase))
+
+	if err.Error() != "login: failed to type [REDACTED]" {
+		t.Errorf("Expecting redacted message, got %s", err.Error())
+	}
+
+	if !errors.Is(err, errBase) {
+		t.Error("Expecting redacted error wrapping the original")
+	}
+
+	if r.RedactError(nil) != nil {
+		t.Error("Expecting nil for nil error")
+	}
+
+	if r.RedactError(errBase) == errBase {
+		t.Error("Expecting new error for message having secret")
+	}
+
+	errClean := errors.New("clean")
+	if r.RedactError(errClean) != errClean {
+		t.Error("Expecting error as is without secret")
+	}
+}
+
+func TestGetRegistersSecret(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	if err := Set("label", "url", "user", "registered-by-get"); err != nil {
+		t.Fatal(err)
+	}
+	Secrets.Remove("registered-by-get")
+
+	if _, _, err := Get("label", "url"); err != nil {
+		t.Fatal(err)
+	}
+	defer Secrets.Remove("registered-by-get")
+
+	if Redact("registered-by-get") != Redacted {
+		t.Error("Expecting secret got from store redacted")
+	}
+}
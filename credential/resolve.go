@@ -0,0 +1,264 @@
+package credential
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// ErrUnresolved is returned when no source in the chain supplies the id or the secret
+var ErrUnresolved = errors.New("credential is not resolved")
+
+// ErrRejected is wrapped by errors of a verification, like a failed login, rejecting a credential
+var ErrRejected = errors.New("credential is rejected")
+
+// Resolved is a credential with names of the sources supplied its id and secret
+type Resolved struct {
+	ID     string
+	Secret string
+
+	IDSource     string
+	SecretSource string
+}
+
+// Complete tells both of id and secret are resolved
+func (r *Resolved) Complete() bool {
+	return r.ID != "" && r.Secret != ""
+}
+
+// Source supplies id, secret or both of a credential
+type Source interface {
+	// Name tells where the values came from like env:NAME, never the values themselves
+	Name() string
+	// Lookup returns values this source has, empty for a value it doesn't have.
+	// partial has values resolved by previous sources.
+	Lookup(partial Resolved) (id string, secret string, err error)
+}
+
+// SourceFunc makes a Source of a name and a lookup func
+func SourceFunc(name string, lookup func(partial Resolved) (string, string, error)) Source {
+	return funcSource{name: name, lookup: lookup}
+}
+
+type funcSource struct {
+	name   string
+	lookup func(partial Resolved) (string, string, error)
+}
+
+func (s funcSource) Name() string {
+	return s.name
+}
+
+func (s funcSource) Lookup(partial Resolved) (string, string, error) {
+	return s.lookup(partial)
+}
+
+// Resolver walks Sources in order filling id and secret not supplied by previous sources
+type Resolver struct {
+	Sources []Source
+}
+
+func NewResolver(sources ...Source) *Resolver {
+	return &Resolver{Sources: sources}
+}
+
+// Resolve stops at the first source completing the credential, the resolved secret is registered to Secrets
+// until Secrets.Remove is called with it.
+// An error of a source stops the chain so that a canceled prompt or a broken store is reported instead of ErrUnresolved.
+func (r *Resolver) Resolve() (*Resolved, error) {
+	resolved := &Resolved{}
+
+	for _, s := range r.Sources {
+		if resolved.Complete() {
+			break
+		}
+
+		id, secret, err := s.Lookup(*resolved)
+		if err != nil {
+			return resolved, fmt.Errorf("failed to resolve credential from %s: %w", s.Name(), err)
+		}
+
+		if resolved.ID == "" && id != "" {
+			resolved.ID, resolved.IDSource = id, s.Name()
+		}
+
+		if resolved.Secret == "" && secret != "" {
+			resolved.Secret, resolved.SecretSource = secret, s.Name()
+			Secrets.Add(secret)
+		}
+	}
+
+	if !resolved.Complete() {
+		return resolved, fmt.Errorf("%w: id from %s, secret from %s, tried %s", ErrUnresolved,
+			sourceOrNone(resolved.IDSource), sourceOrNone(resolved.SecretSource), strings.Join(r.names(), ", "))
+	}
+
+	return resolved, nil
+}
+
+func (r *Resolver) names() []string {
+	names := make([]string, 0, len(r.Sources))
+	for _, s := range r.Sources {
+		names = append(names, s.Name())
+	}
+
+	return names
+}
+
+func sourceOrNone(name string) string {
+	if name == "" {
+		return "none"
+	}
+
+	return name
+}
+
+// Explicit supplies values given by code or command line flags
+func Explicit(id, secret string) Source {
+	return SourceFunc("explicit", func(Resolved) (string, string, error) {
+		return id, secret, nil
+	})
+}
+
+// Env supplies values of environment variables, an empty name is skipped
+func Env(idVar, secretVar string) Source {
+	return SourceFunc("env:"+strings.Join(nonEmpty(idVar, secretVar), ","), func(Resolved) (string, string, error) {
+		return getenv(idVar), getenv(secretVar), nil
+	})
+}
+
+// DotEnv supplies values of keys in a dotenv file, a missing file supplies nothing.
+// Lines are KEY=VALUE with optional export prefix, # comments and single or double quoted values.
+func DotEnv(path, idKey, secretKey string) Source {
+	return SourceFunc("dotenv:"+path, func(Resolved) (string, string, error) {
+		values, err := ReadDotEnv(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", nil
+		} else if err != nil {
+			return "", "", err
+		}
+
+		return values[idKey], values[secretKey], nil
+	})
+}
+
+// SecretFile supplies contents of files like docker or kubernetes mounted secrets, an empty path or missing file is skipped.
+// Trailing new lines are trimmed.
+func SecretFile(idPath, secretPath string) Source {
+	return SourceFunc("file:"+strings.Join(nonEmpty(idPath, secretPath), ","), func(Resolved) (string, string, error) {
+		id, err := readSecretFile(idPath)
+		if err != nil {
+			return "", "", err
+		}
+
+		secret, err := readSecretFile(secretPath)
+		if err != nil {
+			return "", "", err
+		}
+
+		return id, secret, nil
+	})
+}
+
+// NativeStore supplies a credential stored with Set
+func NativeStore(lbl, url string) Source {
+	return SourceFunc("store:"+lbl, func(Resolved) (string, string, error) {
+		id, secret, err := Get(lbl, url)
+		if credentials.IsErrCredentialsNotFound(err) {
+			return "", "", nil
+		}
+
+		return id, secret, err
+	})
+}
+
+// ReadDotEnv reads KEY=VALUE lines of a dotenv file
+func ReadDotEnv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expecting KEY=VALUE", path, lineNo)
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, errUnquote := strconv.Unquote(value)
+			if errUnquote != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, errUnquote)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+
+		values[key] = value
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func getenv(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return os.Getenv(name)
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,86 @@
+package credential
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePrecedence(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	dir := t.TempDir()
+
+	dotenv := filepath.Join(dir, ".env")
+	if err := os.WriteFile(dotenv, []byte("# comment\nexport APP_ID=dotenv-user\nAPP_PASSWORD=\"dotenv \\\"password\\\"\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("file-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set("label", "url", "store-user", "store-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("RESOLVE_TEST_ID", "env-user")
+
+	r := NewResolver(
+		Explicit("", ""),
+		Env("RESOLVE_TEST_ID", "RESOLVE_TEST_PASSWORD"),
+		SecretFile(filepath.Join(dir, "missing"), secretFile),
+		DotEnv(dotenv, "APP_ID", "APP_PASSWORD"),
+		NativeStore("label", "url"),
+	)
+
+	resolved, err := r.Resolve()
+	if err != nil {
+		t.Fatalf("Expecting resolved, got %v", err)
+	}
+
+	if resolved.ID != "env-user" || resolved.IDSource != "env:RESOLVE_TEST_ID,RESOLVE_TEST_PASSWORD" {
+		t.Errorf("Expecting id from env, got %s from %s", resolved.ID, resolved.IDSource)
+	}
+
+	if resolved.Secret != "file-password" || resolved.SecretSource != "file:"+filepath.Join(dir, "missing")+","+secretFile {
+		t.Errorf("Expecting secret from file, got %s from %s", resolved.Secret, resolved.SecretSource)
+	}
+
+	resolved, err = NewResolver(DotEnv(filepath.Join(dir, "missing.env"), "APP_ID", "APP_PASSWORD"), DotEnv(dotenv, "APP_ID", "APP_PASSWORD")).Resolve()
+	if err != nil || resolved.Secret != `dotenv "password"` || resolved.IDSource != "dotenv:"+dotenv {
+		t.Errorf("Expecting values from dotenv, got %+v, %v", resolved, err)
+	}
+
+	resolved, err = NewResolver(NativeStore("label", "url")).Resolve()
+	if err != nil || resolved.ID != "store-user" || resolved.SecretSource != "store:label" {
+		t.Errorf("Expecting values from store, got %+v, %v", resolved, err)
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	defer SetStore(NewMemoryStore())()
+
+	if _, err := NewResolver(Explicit("user", ""), NativeStore("label", "url")).Resolve(); !errors.Is(err, ErrUnresolved) {
+		t.Errorf("Expecting ErrUnresolved, got %v", err)
+	}
+
+	errStore := errors.New("store is broken")
+	defer SetStore(&FailingStore{Err: errStore})()
+
+	called := false
+	after := SourceFunc("after", func(Resolved) (string, string, error) {
+		called = true
+		return "user", "password", nil
+	})
+
+	if _, err := NewResolver(NativeStore("label", "url"), after).Resolve(); !errors.Is(err, errStore) {
+		t.Errorf("Expecting store error, got %v", err)
+	}
+
+	if called {
+		t.Error("Expecting chain stopped on error")
+	}
+}
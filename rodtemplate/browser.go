@@ -1,9 +1,13 @@
 package rodtemplate
 
 import (
-	"log"
+	"errors"
+	"time"
 
 	"github.com/go-rod/rod"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/darimuri/go-lib/credential"
 )
 
 type LoginHandler struct {
@@ -23,6 +27,10 @@ type LoginHandler struct {
 
 	EnvPassword string
 
+	// CredentialSources are looked up for ID, Password not given or set in os environment variables,
+	// like credential.DotEnv, credential.SecretFile, credential.NativeStore or cmdutil.PromptSource
+	CredentialSources []credential.Source
+
 	CaptchaHandler           func(pt *PageTemplate) error
 	LoginLinkHandler         func(pt *PageTemplate) error
 	LoginBeforeSuccessCheckHandler func(pt *PageTemplate) (bool, error)
@@ -33,22 +41,70 @@ type LoginHandler struct {
 
 type BrowserTemplate struct {
 	*rod.Browser
+	// Logger receives records of the browser and its pages, the one set by SetDefaultLogger if nil
+	Logger Logger
+	// TracerProvider starts spans of the browser and its pages, the one set by SetDefaultTracerProvider if nil
+	TracerProvider trace.TracerProvider
+	// Metrics receives measurements of the browser and its pages, the one set by SetDefaultMetrics if nil
+	Metrics Metrics
+}
+
+func (b *BrowserTemplate) logger() Logger {
+	if b.Logger != nil {
+		return redacting(b.Logger)
+	}
+
+	return redacting(defaultLogger)
+}
+
+// Page returns a template of page sharing the logger, tracer provider and metrics of b
+func (b *BrowserTemplate) Page(page *rod.Page) *PageTemplate {
+	return &PageTemplate{P: page, Logger: b.Logger, TracerProvider: b.TracerProvider, Metrics: b.Metrics}
+}
+
+// Login opens LoginGateURL and logs in unless already logged in.
+// Secrets known to credential.Secrets are masked in the returned error.
+func (b *BrowserTemplate) Login(h LoginHandler) (pt *PageTemplate, err error) {
+	ctx, span := startSpan(b.TracerProvider, b.GetContext(), "rodtemplate.Login", AttrURL.String(h.LoginGateURL))
+	defer endSpan(span, &err)
+
+	// pages opened while logging in have spans under the login span
+	traced := &BrowserTemplate{Browser: b.Browser.Context(ctx), Logger: b.Logger, TracerProvider: b.TracerProvider, Metrics: b.Metrics}
+
+	started := time.Now()
+
+	pt, outcome, err := traced.login(h)
+	if errors.Is(err, credential.ErrRejected) {
+		outcome = LoginRejected
+	}
+
+	metrics := b.Metrics
+	if metrics == nil {
+		metrics = defaultMetrics
+	}
+	metrics.ObserveLogin(siteOf(h.LoginGateURL), outcome, time.Since(started))
+
+	if pt != nil {
+		pt = pt.derive(pt.P.Context(b.GetContext()))
+	}
+
+	return pt, credential.RedactError(err)
 }
 
-func (b *BrowserTemplate) Login(h LoginHandler) (*PageTemplate, error) {
+func (b *BrowserTemplate) login(h LoginHandler) (*PageTemplate, LoginOutcome, error) {
 	var pt *PageTemplate
 
-	log.Println("go to login gate", h.LoginGateURL)
+	b.logger().Info("go to login gate", "url", h.LoginGateURL)
 
 	page := b.MustPage(h.LoginGateURL)
 	page.MustWaitRequestIdle()
 
-	pt = &PageTemplate{P: page}
+	pt = b.Page(page)
 	pt.MaximizeToWindowBounds()
 
 	pages, err := b.Browser.Pages()
 	if err != nil {
-		return nil, err
+		return nil, LoginErrored, err
 	}
 
 	for _, p := range pages {
@@ -59,45 +115,47 @@ func (b *BrowserTemplate) Login(h LoginHandler) (*PageTemplate, error) {
 	}
 
 	if h.LoginSuccessSelector != "" && pt.Has(h.LoginSuccessSelector) {
-		return pt, nil
+		return pt, LoginAlready, nil
 	} else if h.LoginBeforeSuccessCheckHandler != nil {
 		succ, errHandle := h.LoginBeforeSuccessCheckHandler(pt)
 		if succ && errHandle == nil {
-			return pt, nil
+			return pt, LoginAlready, nil
 		} else if errHandle != nil {
-			log.Printf("failed to check login succes for error %s", errHandle.Error())
+			b.logger().Warn("failed to check login success", "url", pt.URL(), "error", errHandle)
 		}
 	}
 
 
 	if h.LoginURL != "" {
-		log.Println("go to login page", h.LoginURL)
+		b.logger().Info("go to login page", "url", h.LoginURL)
 		if err = pt.Navigate(h.LoginURL); err != nil {
-			return nil, err
+			return nil, LoginErrored, err
 		}
 	} else if h.LoginLinkHandler != nil {
-		log.Println("go to login page", "with LoginLinkHandler")
+		b.logger().Info("go to login page with LoginLinkHandler", "url", pt.URL())
 		if err = h.LoginLinkHandler(pt); err != nil {
-			return nil, err
+			return nil, LoginErrored, err
 		}
 	} else {
-		log.Println("go to login page", "with LoginLinkSelector")
+		b.logger().Info("go to login page with LoginLinkSelector", "url", pt.URL(), "selector", h.LoginLinkSelector)
 		pt.ClickWhenAvailable(h.LoginLinkSelector)
 	}
 
 	login := &Login{PageTemplate: pt, Handler: h}
 
-	log.Println("validate login")
+	b.logger().Debug("validate login")
 	if err = login.Validate(); err != nil {
-		return nil, err
+		return nil, LoginErrored, err
 	}
+	// the password registered by Validate is released after submitting, Submit masks its error itself
+	defer credential.Secrets.Remove(login.Handler.Password)
 
-	log.Println("submit login")
+	b.logger().Info("submit login", "url", pt.URL())
 	if err = login.Submit(b.Browser); err != nil {
-		return nil, err
+		return nil, LoginErrored, err
 	}
 
-	return pt, nil
+	return pt, LoginSucceeded, nil
 }
 
 func NewBrowserTemplate(b *rod.Browser) *BrowserTemplate {
@@ -1,15 +1,87 @@
 package rodtemplate
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrInspectNotFound is wrapped by InspectError when a required selector is missing
+var ErrInspectNotFound = errors.New("element is not found")
+
+// InspectError is an error raised at a selector path of InspectChain
+type InspectError struct {
+	Path []string
+	Err  error
+}
+
+func (e *InspectError) Error() string {
+	return fmt.Sprintf("%s: %s", strings.Join(e.Path, " > "), e.Err.Error())
+}
+
+func (e *InspectError) Unwrap() error {
+	return e.Err
+}
+
+// InspectErrors is every error collected by a chain and its sub chains
+type InspectErrors []error
+
+func (e InspectErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e InspectErrors) Unwrap() []error {
+	return e
+}
+
+// Is tells any of e matches target, errors.Is follows Unwrap() []error only from go 1.20
+func (e InspectErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of e matching target like errors.As
+func (e InspectErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inspectCollector is shared by a root chain and every chain derived from it
+type inspectCollector struct {
+	errors []error
+}
+
+func (c *inspectCollector) add(selectors []string, err error) {
+	if err == nil {
+		return
+	}
+
+	path := make([]string, len(selectors))
+	copy(path, selectors)
+
+	c.errors = append(c.errors, &InspectError{Path: path, Err: err})
+}
+
 func NewInspectChain(template ElementSelector) *InspectChain {
 	return &InspectChain{
 		et:        template,
 		selectors: []string{},
-		errors:    []error{},
+		collector: &inspectCollector{},
 	}
 }
 
@@ -18,113 +90,209 @@ type InspectChain struct {
 	prev      *InspectChain
 	et        ElementSelector
 	selectors []string
-	errors    []error
+	collector *inspectCollector
 
 	returnSelf bool
 }
 
 type InspectOneFunc func(el *ElementTemplate) error
 type InspectEachFunc func(idx int, el *ElementTemplate) error
+type InspectItemFunc func(idx int, item *InspectChain)
 
 //SelfChain ... returns self chain which returns self(chain) after ForOne
 //which means no chain information are preserved.
 func (ic *InspectChain) SelfChain() *InspectChain {
-	return &InspectChain{
-		skipNext:  ic.skipNext,
-		prev:      ic.prev,
-		et:        ic.et,
-		selectors: ic.selectors,
-		errors:    ic.errors,
+	self := ic.derive(ic.et, ic.selectors, ic.skipNext)
+	self.prev = ic.prev
+	self.returnSelf = true
 
-		returnSelf: true,
+	return self
+}
+
+//Err ... returns every error collected by the chain and its sub chains, nil if there is none
+func (ic *InspectChain) Err() error {
+	if len(ic.collector.errors) == 0 {
+		return nil
 	}
 
+	return InspectErrors(ic.Errors())
 }
 
-//ForOne ... find one element, call f and return found element chain
-func (ic *InspectChain) ForOne(selector string, panicIfNot, stopOnError bool, f InspectOneFunc) *InspectChain {
-	selectors := append(ic.selectors, selector)
+//Errors ... returns every error collected by the chain and its sub chains
+func (ic *InspectChain) Errors() []error {
+	errs := make([]error, len(ic.collector.errors))
+	copy(errs, ic.collector.errors)
 
-	if ic.skipNext {
-		return &InspectChain{
-			skipNext:  true,
-			prev:      ic,
-			et:        nil,
-			selectors: selectors,
-			errors:    append(ic.errors, nil),
+	return errs
+}
+
+//Required ... find one element, call f and return found element chain.
+//missing element is collected as ErrInspectNotFound and skips the rest of chain
+func (ic *InspectChain) Required(selector string, f InspectOneFunc) *InspectChain {
+	return ic.one(selector, true, true, f)
+}
+
+//Optional ... find one element, call f if found and return found element chain.
+//missing element skips the rest of chain without error
+func (ic *InspectChain) Optional(selector string, f InspectOneFunc) *InspectChain {
+	if !ic.skipNext && ic.et != nil {
+		if found, err := ic.has(selector); err == nil && !found {
+			return ic.derive(nil, append(ic.path(), selector), true)
 		}
 	}
 
-	var err error
-	var el *ElementTemplate
+	return ic.one(selector, false, true, f)
+}
+
+//Each ... find elements and call f with a sub chain of each element then return self(chain).
+//errors of sub chains are collected into this chain
+func (ic *InspectChain) Each(selector string, f InspectItemFunc) *InspectChain {
+	selectors := append(ic.path(), selector)
+
+	if ic.skipNext {
+		return ic
+	}
 
 	if ic.et == nil {
-		err = fmt.Errorf("element is nil for %s", strings.Join(ic.selectors, " > "))
-	} else if ic.et.Has(selector) {
-		el = ic.et.El(selector)
-		err = f(el)
-	} else if panicIfNot {
-		panic(fmt.Errorf("%s is not found", strings.Join(selectors, " > ")))
-	} else {
-		err = f(nil)
+		ic.collector.add(ic.selectors, errors.New("element is nil"))
+		return ic
 	}
 
-	if ic.returnSelf {
-		return &InspectChain{
-			skipNext:  ic.skipNext,
-			prev:      ic.prev,
-			et:        ic.et,
-			selectors: ic.selectors,
-			errors:    ic.errors,
-
-			returnSelf: true,
-		}
+	els, err := ic.els(selector)
+	if err != nil {
+		ic.collector.add(selectors, err)
+		return ic
 	}
 
-	return &InspectChain{
-		skipNext:  err != nil && stopOnError,
-		prev:      ic,
-		et:        el,
-		selectors: selectors,
-		errors:    append(ic.errors, err),
+	for idx, el := range els {
+		itemSelectors := append(ic.path(), fmt.Sprintf("%s[%d]", selector, idx))
+		f(idx, ic.derive(el, itemSelectors, false))
 	}
+
+	return ic
+}
+
+//ForOne ... find one element, call f and return found element chain.
+//f is called with nil if element is missing and required is false.
+func (ic *InspectChain) ForOne(selector string, required, stopOnError bool, f InspectOneFunc) *InspectChain {
+	return ic.one(selector, required, stopOnError, f)
 }
 
-//ForEach ... find elements, call f for each elements and return self(chain)
-func (ic *InspectChain) ForEach(selector string, panicIfNot, stopOnError bool, f InspectEachFunc) *InspectChain {
-	selectors := append(ic.selectors, selector)
+//ForEach ... find elements, call f for each elements and return self(chain), a chain of the same element and path.
+//the rest of chain is skipped if any f failed and stopOnError is true
+func (ic *InspectChain) ForEach(selector string, required, stopOnError bool, f InspectEachFunc) *InspectChain {
+	selectors := append(ic.path(), selector)
 
 	if ic.skipNext {
-		return &InspectChain{
-			skipNext:  true,
-			prev:      ic.prev,
-			et:        nil,
-			selectors: selectors,
-			errors:    append(ic.errors, nil),
+		return ic.derive(nil, ic.path(), true)
+	}
+
+	failed := false
+	fail := func(path []string, err error) {
+		failed = true
+		ic.collector.add(path, err)
+	}
+
+	if ic.et == nil {
+		fail(ic.selectors, errors.New("element is nil"))
+	} else if els, err := ic.els(selector); err != nil {
+		fail(selectors, err)
+	} else if len(els) == 0 && required {
+		fail(selectors, ErrInspectNotFound)
+	} else {
+		for idx, el := range els {
+			item := el
+			if err = ic.call(func(*ElementTemplate) error { return f(idx, item) }, el); err != nil {
+				fail(append(ic.path(), fmt.Sprintf("%s[%d]", selector, idx)), err)
+			}
 		}
 	}
 
+	next := ic.derive(ic.et, ic.path(), failed && stopOnError)
+	next.prev = ic.prev
+
+	return next
+}
+
+func (ic *InspectChain) one(selector string, required, stopOnError bool, f InspectOneFunc) *InspectChain {
+	selectors := append(ic.path(), selector)
+
+	if ic.skipNext {
+		return ic.derive(nil, selectors, true)
+	}
+
 	var err error
-	var els ElementsTemplate
+	var el *ElementTemplate
 
 	if ic.et == nil {
-		err = fmt.Errorf("element is nil for %s", strings.Join(ic.selectors, " > "))
-	} else if ic.et.Has(selector) {
-		els = ic.et.Els(selector)
-		for idx, el := range els {
-			err = f(idx, el)
+		err = errors.New("element is nil")
+		ic.collector.add(ic.selectors, err)
+	} else if found, errHas := ic.has(selector); errHas != nil {
+		err = errHas
+		ic.collector.add(selectors, err)
+	} else if found {
+		if err = catch(func() { el = ic.et.El(selector) }); err == nil {
+			err = ic.call(f, el)
 		}
-	} else if panicIfNot {
-		panic(fmt.Errorf("%s is not found", strings.Join(selectors, " > ")))
+		ic.collector.add(selectors, err)
+	} else if required {
+		err = ErrInspectNotFound
+		ic.collector.add(selectors, err)
 	} else {
-		//TODO: how to repeat?
+		err = ic.call(f, nil)
+		ic.collector.add(selectors, err)
+	}
+
+	if ic.returnSelf {
+		return ic.SelfChain()
+	}
+
+	if el == nil {
+		return ic.derive(nil, selectors, err != nil && stopOnError)
+	}
+
+	return ic.derive(el, selectors, err != nil && stopOnError)
+}
+
+// call runs f turning a panic from Must* calls into an error
+func (ic *InspectChain) call(f InspectOneFunc, el *ElementTemplate) (err error) {
+	errCatch := catch(func() { err = f(el) })
+	if errCatch != nil {
+		return errCatch
 	}
 
+	return err
+}
+
+func (ic *InspectChain) has(selector string) (found bool, err error) {
+	err = catch(func() { found = ic.et.Has(selector) })
+	return found, err
+}
+
+func (ic *InspectChain) els(selector string) (els ElementsTemplate, err error) {
+	err = catch(func() {
+		if ic.et.Has(selector) {
+			els = ic.et.Els(selector)
+		}
+	})
+
+	return els, err
+}
+
+// path returns a copy of selectors so that appending to it doesn't overwrite siblings
+func (ic *InspectChain) path() []string {
+	path := make([]string, len(ic.selectors), len(ic.selectors)+1)
+	copy(path, ic.selectors)
+
+	return path
+}
+
+func (ic *InspectChain) derive(et ElementSelector, selectors []string, skipNext bool) *InspectChain {
 	return &InspectChain{
-		skipNext:  err != nil && stopOnError,
-		prev:      ic.prev,
-		et:        ic.et,
+		skipNext:  skipNext,
+		prev:      ic,
+		et:        et,
 		selectors: selectors,
-		errors:    append(ic.errors, err),
+		collector: ic.collector,
 	}
 }
@@ -0,0 +1,95 @@
+package rodtemplate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInspectChain(t *testing.T) {
+	_, _, pt := openFixture(t, "table.html")
+
+	var name string
+	tags := make([]string, 0)
+
+	chain := NewInspectChain(pt)
+	product := chain.Required("#product", func(el *ElementTemplate) error { return nil })
+	product.Required(".name", func(el *ElementTemplate) error {
+		name = el.MustText()
+		return nil
+	})
+	product.Required(".missing", func(el *ElementTemplate) error { return nil }).
+		Required(".never", func(el *ElementTemplate) error { return nil })
+	product.Optional(".discount", func(el *ElementTemplate) error {
+		return errors.New("must not be called")
+	})
+	product.Each(".tags li", func(idx int, item *InspectChain) {
+		item.SelfChain().ForOne("b", false, false, func(el *ElementTemplate) error {
+			if el == nil {
+				return errors.New("no bold tag")
+			}
+			return nil
+		})
+		tags = append(tags, item.et.(*ElementTemplate).MustText())
+	})
+
+	if name != "Apple" {
+		t.Errorf("Expecting Apple, got %s", name)
+	}
+
+	if strings.Join(tags, ",") != "fruit,red" {
+		t.Errorf("Expecting fruit,red, got %v", tags)
+	}
+
+	errs := chain.Errors()
+	if len(errs) != 3 {
+		t.Fatalf("Expecting 3 errors, got %v", chain.Err())
+	}
+
+	if !errors.Is(errs[0], ErrInspectNotFound) || !strings.HasPrefix(errs[0].Error(), "#product > .missing:") {
+		t.Errorf("Expecting not found error with path, got %v", errs[0])
+	}
+
+	if !errors.Is(chain.Err(), ErrInspectNotFound) {
+		t.Errorf("Expecting Err to be not found, got %v", chain.Err())
+	}
+
+	if !strings.HasPrefix(errs[2].Error(), "#product > .tags li[1] > b:") {
+		t.Errorf("Expecting error with item path, got %v", errs[2])
+	}
+}
+
+func TestInspectChainForEach(t *testing.T) {
+	_, _, pt := openFixture(t, "table.html")
+
+	chain := NewInspectChain(pt).ForEach("#product .tags li", true, false, func(idx int, el *ElementTemplate) error {
+		return errors.New(el.MustText())
+	})
+	chain.ForEach(".missing", true, false, func(idx int, el *ElementTemplate) error { return nil })
+
+	if err := chain.Err(); err == nil || err.Error() != strings.Join([]string{
+		"#product .tags li[0]: fruit",
+		"#product .tags li[1]: red",
+		".missing: element is not found",
+	}, "\n") {
+		t.Errorf("Expecting every error, got %v", err)
+	}
+}
+
+func TestInspectErrorsIs(t *testing.T) {
+	other := errors.New("other")
+	err := error(InspectErrors{other, &InspectError{Path: []string{"#a"}, Err: ErrInspectNotFound}})
+
+	if !errors.Is(err, ErrInspectNotFound) || !errors.Is(err, other) {
+		t.Errorf("Expecting both errors matched, got %v", err)
+	}
+
+	if errors.Is(err, ErrLoginFailed) {
+		t.Errorf("Expecting ErrLoginFailed not matched, got %v", err)
+	}
+
+	var inspectErr *InspectError
+	if !errors.As(err, &inspectErr) || inspectErr.Path[0] != "#a" {
+		t.Errorf("Expecting InspectError of #a, got %v", inspectErr)
+	}
+}
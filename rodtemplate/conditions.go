@@ -0,0 +1,244 @@
+package rodtemplate
+
+import (
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ConditionPolicy is the interval of WaitUntil, its Timeout is replaced by the timeout of WaitUntil
+var ConditionPolicy = RetryPolicy{Backoff: ConstantBackoff(100 * time.Millisecond)}
+
+// Conditions builds conditions on elements under a page or an element, compose them with AllOf, AnyOf and Not.
+// A failure like a missing element or a navigation in progress makes a condition unsatisfied rather than an error.
+type Conditions struct {
+	scope  ElementSelector
+	page   *rod.Page
+	logger Logger
+}
+
+// Cond returns conditions on elements of the page
+func (p *PageTemplate) Cond() Conditions {
+	return Conditions{scope: p, page: p.P, logger: p.logger()}
+}
+
+// Cond returns conditions on descendants of the element
+func (e ElementTemplate) Cond() Conditions {
+	return Conditions{scope: e, page: e.Page(), logger: e.pageTemplate().logger()}
+}
+
+// WaitUntil waits until condition is satisfied or timeout is elapsed, it never times out if timeout is 0
+func (p *PageTemplate) WaitUntil(target string, timeout time.Duration, condition Condition) error {
+	policy := ConditionPolicy
+	policy.Timeout = timeout
+
+	_, err := p.retry(p.context(), WaitKindWaitUntil, target, policy, condition, nil)
+
+	return err
+}
+
+// WaitUntil waits until condition is satisfied or timeout is elapsed, it never times out if timeout is 0
+func (e ElementTemplate) WaitUntil(target string, timeout time.Duration, condition Condition) error {
+	policy := ConditionPolicy
+	policy.Timeout = timeout
+
+	_, err := e.pageTemplate().retry(e.GetContext(), WaitKindWaitUntil, target, policy, condition, nil)
+
+	return err
+}
+
+// check makes a Condition of f treating its panic or error as unsatisfied
+func (c Conditions) check(name string, f func() (bool, error)) Condition {
+	return func() (ok bool, err error) {
+		if errCatch := catch(func() { ok, err = f() }); errCatch != nil {
+			err = errCatch
+		}
+
+		if err != nil {
+			c.logger.Debug("condition is not satisfied for error", "condition", name, "error", err)
+			return false, nil
+		}
+
+		return ok, nil
+	}
+}
+
+// el returns the first element of selector, nil if not found
+func (c Conditions) el(selector string) *ElementTemplate {
+	if !c.scope.Has(selector) {
+		return nil
+	}
+
+	return c.scope.El(selector)
+}
+
+// Exists is satisfied when selector matches an element
+func (c Conditions) Exists(selector string) Condition {
+	return c.check("exists "+selector, func() (bool, error) {
+		return c.scope.Has(selector), nil
+	})
+}
+
+// Visible is satisfied when the first element of selector is visible
+func (c Conditions) Visible(selector string) Condition {
+	return c.check("visible "+selector, func() (bool, error) {
+		el := c.el(selector)
+		if el == nil {
+			return false, nil
+		}
+
+		return el.Visible()
+	})
+}
+
+// Hidden is satisfied when the first element of selector is not visible or there is no element of selector
+func (c Conditions) Hidden(selector string) Condition {
+	return c.check("hidden "+selector, func() (bool, error) {
+		el := c.el(selector)
+		if el == nil {
+			return true, nil
+		}
+
+		visible, err := el.Visible()
+		return !visible, err
+	})
+}
+
+// Detached is satisfied when el is removed from the document
+func (c Conditions) Detached(el *ElementTemplate) Condition {
+	return c.check("detached", func() (bool, error) {
+		res, err := el.Eval(`function() { return !this.isConnected }`)
+		if err != nil {
+			// the node of a removed element can be released already
+			return IsObjectNotFoundError(err), nil
+		}
+
+		return res.Value.Bool(), nil
+	})
+}
+
+// TextMatches is satisfied when the text of the first element of selector matches re
+func (c Conditions) TextMatches(selector string, re *regexp.Regexp) Condition {
+	return c.check("text of "+selector+" matches "+re.String(), func() (bool, error) {
+		el := c.el(selector)
+		if el == nil {
+			return false, nil
+		}
+
+		text, err := el.Text()
+		return err == nil && re.MatchString(text), err
+	})
+}
+
+// AttributeEquals is satisfied when the attribute of the first element of selector is value
+func (c Conditions) AttributeEquals(selector, name, value string) Condition {
+	return c.check("attribute "+name+" of "+selector+" equals", func() (bool, error) {
+		el := c.el(selector)
+		if el == nil {
+			return false, nil
+		}
+
+		attr, err := el.Attribute(name)
+		return err == nil && attr != nil && *attr == value, err
+	})
+}
+
+// CountAtLeast is satisfied when selector matches n or more elements
+func (c Conditions) CountAtLeast(selector string, n int) Condition {
+	return c.check("count of "+selector, func() (bool, error) {
+		if !c.scope.Has(selector) {
+			return n <= 0, nil
+		}
+
+		return len(c.scope.Els(selector)) >= n, nil
+	})
+}
+
+// URLMatches is satisfied when the url of the page matches re
+func (c Conditions) URLMatches(re *regexp.Regexp) Condition {
+	return c.check("url matches "+re.String(), func() (bool, error) {
+		info, err := c.page.Info()
+		if err != nil {
+			return false, err
+		}
+
+		return re.MatchString(info.URL), nil
+	})
+}
+
+// JS is satisfied when expression is truthy, this is the element for conditions of an element.
+// expression is like document.readyState === 'complete'.
+func (c Conditions) JS(expression string) Condition {
+	js := `function() { return Boolean(` + expression + `) }`
+
+	return c.check("js "+expression, func() (bool, error) {
+		var res *proto.RuntimeRemoteObject
+		var err error
+
+		if el, ok := c.scope.(ElementTemplate); ok {
+			res, err = el.Eval(js)
+		} else {
+			res, err = c.page.Eval(js)
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		return res.Value.Bool(), nil
+	})
+}
+
+// Stable is satisfied when the box of the first element of selector is the same as the one at the previous check
+func (c Conditions) Stable(selector string) Condition {
+	var prev *proto.DOMRect
+
+	return c.check("stable "+selector, func() (bool, error) {
+		el := c.el(selector)
+		if el == nil {
+			prev = nil
+			return false, nil
+		}
+
+		shape, err := el.Shape()
+		if err != nil || len(shape.Quads) == 0 {
+			prev = nil
+			return false, err
+		}
+
+		box := shape.Box()
+		stable := prev != nil && *prev == *box
+		prev = box
+
+		return stable, nil
+	})
+}
+
+// Response is satisfied once a response of url matching re is received after Response is called.
+// Call stop when it is not waited anymore, it is stopped when satisfied.
+func (c Conditions) Response(re *regexp.Regexp) (condition Condition, stop func()) {
+	var received int32
+
+	page, cancel := c.page.WithCancel()
+
+	wait := page.EachEvent(func(e *proto.NetworkResponseReceived) bool {
+		if re.MatchString(e.Response.URL) {
+			atomic.StoreInt32(&received, 1)
+			return true
+		}
+
+		return false
+	})
+
+	go func() {
+		defer cancel()
+		wait()
+	}()
+
+	return func() (bool, error) {
+		return atomic.LoadInt32(&received) == 1, nil
+	}, cancel
+}
@@ -0,0 +1,80 @@
+package rodtemplate
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWaitUntil(t *testing.T) {
+	_, _, pt := openFixture(t, "conditions.html")
+
+	response, stop := pt.Cond().Response(regexp.MustCompile(`/gate\.html\?loaded=1$`))
+	defer stop()
+
+	spinner := pt.El("#spinner")
+
+	c := pt.Cond()
+	err := pt.WaitUntil("loaded", 5*time.Second, AllOf(
+		c.TextMatches("#status", regexp.MustCompile(`^Loaded \d+ items$`)),
+		c.AttributeEquals("#status", "data-state", "done"),
+		c.CountAtLeast(".item", 3),
+		c.Hidden("#spinner"),
+		c.Visible("#status"),
+		c.URLMatches(regexp.MustCompile(`loaded=1`)),
+		c.JS(`document.readyState === 'complete'`),
+		c.Stable("#list"),
+		response,
+	))
+	if err != nil {
+		t.Fatalf("Expecting conditions satisfied, got %v", err)
+	}
+
+	list := pt.El("#list")
+	if err = list.WaitUntil("items", time.Second, list.Cond().CountAtLeast(".item", 3)); err != nil {
+		t.Errorf("Expecting 3 items in the list, got %v", err)
+	}
+
+	if err = list.WaitUntil("list", time.Second, list.Cond().JS(`this.children.length === 3`)); err != nil {
+		t.Errorf("Expecting this to be the list, got %v", err)
+	}
+
+	spinner.MustRemove()
+	if err = pt.WaitUntil("detached", time.Second, c.Detached(spinner)); err != nil {
+		t.Errorf("Expecting spinner detached, got %v", err)
+	}
+
+	var te *TimeoutError
+	err = pt.WaitUntil("missing", 300*time.Millisecond, AnyOf(c.Exists("#missing"), c.CountAtLeast(".item", 4)))
+	if !errors.As(err, &te) || te.Target != "missing" {
+		t.Errorf("Expecting TimeoutError, got %v", err)
+	}
+
+	own := &recordLogger{}
+	pt.Logger = own
+	list = pt.El("#list")
+	if err = list.WaitUntil("more items", 300*time.Millisecond, list.Cond().CountAtLeast(".item", 4)); err == nil {
+		t.Error("Expecting timeout for 4 items")
+	}
+	if len(own.records) == 0 {
+		t.Error("Expecting retries of the element logged to the logger of the page")
+	}
+}
+
+func TestConditionsCheck(t *testing.T) {
+	c := Conditions{logger: NopLogger}
+
+	for name, f := range map[string]func() (bool, error){
+		"panic": func() (bool, error) { panic(errors.New("node is detached")) },
+		"error": func() (bool, error) { return true, errors.New("navigated") },
+	} {
+		if ok, err := c.check(name, f)(); ok || err != nil {
+			t.Errorf("Expecting unsatisfied without error for %s, got %v, %v", name, ok, err)
+		}
+	}
+
+	if ok, err := c.check("ok", func() (bool, error) { return true, nil })(); !ok || err != nil {
+		t.Errorf("Expecting satisfied, got %v, %v", ok, err)
+	}
+}
@@ -17,16 +17,32 @@ type ElementSelector interface {
 
 var _ ElementSelector = (*ElementTemplate)(nil)
 
+// ElementTemplate is an element found by El or Els of a PageTemplate or of another ElementTemplate.
+// It waits, logs, traces and measures with the Logger, TracerProvider and Metrics of the PageTemplate it is found by.
+// An element made from rod with a keyed literal like &ElementTemplate{Element: el} uses the defaults set by
+// SetDefaultLogger, SetDefaultTracerProvider and SetDefaultMetrics instead, use PageTemplate.Wrap to attach its page.
 type ElementTemplate struct {
 	*rod.Element
+	// owner is the page template the element is found by, nil if the element is made from rod
+	owner *PageTemplate
 }
 
 func (e ElementTemplate) El(selector string) *ElementTemplate {
-	return &ElementTemplate{Element: e.MustElement(selector)}
+	return &ElementTemplate{Element: e.MustElement(selector), owner: e.owner}
 }
 
 func (e ElementTemplate) Els(selector string) ElementsTemplate {
-	return toElementsTemplate(e.MustElements(selector))
+	return toElementsTemplate(e.MustElements(selector), e.owner)
+}
+
+// pageTemplate returns a template of the page of the element sharing the logger, tracer provider and metrics of
+// the owner, the defaults if there is no owner
+func (e ElementTemplate) pageTemplate() *PageTemplate {
+	if e.owner == nil {
+		return &PageTemplate{P: e.Page()}
+	}
+
+	return e.owner.derive(e.Page())
 }
 
 func (e ElementTemplate) Has(selector string) bool {
@@ -51,28 +67,27 @@ func (e ElementTemplate) WaitUntilHasForHour(selector string) bool {
 }
 
 func (e ElementTemplate) WaitUntilHas(selector string, sleepDuration time.Duration, deadline time.Duration) bool {
-	deadlineTime := time.Now().Add(deadline)
-	for time.Now().Before(deadlineTime) {
-		if e.Has(selector) {
-			return true
-		}
-		time.Sleep(sleepDuration)
-	}
+	policy := timeoutPolicy(sleepDuration, deadline)
+	condition := Check(func() bool {
+		return e.Has(selector)
+	})
+
+	_, err := e.pageTemplate().retry(e.GetContext(), WaitKindWaitUntilHas, selector, policy, condition, nil)
 
-	return false
+	return err == nil
 }
 
-func toElementsTemplate(elements rod.Elements) ElementsTemplate {
+func toElementsTemplate(elements rod.Elements, owner *PageTemplate) ElementsTemplate {
 	est := make([]*ElementTemplate, 0)
 	for idx := range elements {
-		est = append(est, &ElementTemplate{Element: elements[idx]})
+		est = append(est, &ElementTemplate{Element: elements[idx], owner: owner})
 	}
 
 	return est
 }
 
 func NewElementsTemplate(elements rod.Elements) ElementsTemplate {
-	return toElementsTemplate(elements)
+	return toElementsTemplate(elements, nil)
 }
 
 func (e ElementTemplate) ElE(selector string) (*rod.Element, error) {
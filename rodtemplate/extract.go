@@ -0,0 +1,470 @@
+package rodtemplate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ExtractTag is the struct tag name read by Extract
+const ExtractTag = "rod"
+
+// FieldError is a failure to fill one field in Extract
+type FieldError struct {
+	Field    string
+	Selector string
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	if e.Selector == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Err.Error())
+	}
+
+	return fmt.Sprintf("%s(%s): %s", e.Field, e.Selector, e.Err.Error())
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ExtractError lists every field which failed in Extract
+type ExtractError struct {
+	Fields []*FieldError
+}
+
+func (e *ExtractError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Error())
+	}
+
+	return fmt.Sprintf("failed to extract %d field(s)\n%s", len(e.Fields), strings.Join(messages, "\n"))
+}
+
+// Extract fills dst, a pointer to struct, from elements under es using `rod` struct tags.
+//
+// Tag options are comma separated key=value pairs:
+//
+//	css=<selector>  element to read, the element of es itself if omitted
+//	attr=<name>     read attribute instead of text
+//	html            read outer html instead of text
+//	parse=<type>    int, uint, float, bool or string, defaults to the field type
+//	strip=<chars>   remove every char in chars before parsing, put it last to strip ","
+//	notrim          keep leading and trailing spaces
+//	optional        leave the field as is if element or attribute is missing
+//
+// Struct fields are filled recursively and slice fields are filled from every element
+// matched by css. Pointer fields are left nil when an optional element is missing.
+func Extract(es ElementSelector, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("extract destination must be a non nil pointer to struct")
+	}
+
+	x := &extractor{}
+	x.fillStruct(es, v.Elem(), "")
+
+	if len(x.errors) > 0 {
+		return &ExtractError{Fields: x.errors}
+	}
+
+	return nil
+}
+
+func (p *PageTemplate) Extract(dst interface{}) error {
+	return Extract(p, dst)
+}
+
+func (e ElementTemplate) Extract(dst interface{}) error {
+	return Extract(&e, dst)
+}
+
+type extractOption struct {
+	css      string
+	attr     string
+	html     bool
+	parse    string
+	strip    string
+	notrim   bool
+	optional bool
+}
+
+var extractKeyPattern = regexp.MustCompile(`(?:^|,)(css|attr|html|parse|strip|notrim|optional)(=?)`)
+
+func parseExtractTag(tag string) (extractOption, error) {
+	opt := extractOption{}
+
+	// a flag key must be followed by a comma or the end of tag, otherwise it's a part of a value
+	matches := make([][]int, 0)
+	for _, m := range extractKeyPattern.FindAllStringSubmatchIndex(tag, -1) {
+		if m[4] == m[5] && m[1] < len(tag) && tag[m[1]] != ',' {
+			continue
+		}
+		matches = append(matches, m)
+	}
+
+	if tag != "" && (len(matches) == 0 || matches[0][0] != 0) {
+		return opt, fmt.Errorf("invalid tag %q", tag)
+	}
+
+	for i, m := range matches {
+		key := tag[m[2]:m[3]]
+
+		// a value runs until the next known key so that it may contain commas
+		value := ""
+		if tag[m[4]:m[5]] == "=" {
+			end := len(tag)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			value = tag[m[5]:end]
+		}
+
+		switch key {
+		case "css":
+			opt.css = value
+		case "attr":
+			opt.attr = value
+		case "html":
+			opt.html = true
+		case "parse":
+			opt.parse = value
+		case "strip":
+			opt.strip = value
+		case "notrim":
+			opt.notrim = true
+		case "optional":
+			opt.optional = true
+		}
+	}
+
+	return opt, nil
+}
+
+type extractor struct {
+	errors []*FieldError
+}
+
+func (x *extractor) fail(field, selector string, err error) {
+	x.errors = append(x.errors, &FieldError{Field: field, Selector: selector, Err: err})
+}
+
+func (x *extractor) fillStruct(es ElementSelector, v reflect.Value, prefix string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(ExtractTag)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name := prefix + sf.Name
+
+		opt, err := parseExtractTag(tag)
+		if err != nil {
+			x.fail(name, "", err)
+			continue
+		}
+
+		x.fillField(es, v.Field(i), name, opt)
+	}
+}
+
+func (x *extractor) fillField(es ElementSelector, fv reflect.Value, name string, opt extractOption) {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		x.fillSlice(es, fv, name, opt)
+		return
+	}
+
+	target, found, err := x.selectOne(es, opt.css)
+	if err != nil {
+		x.fail(name, opt.css, err)
+		return
+	}
+
+	if !found {
+		if !opt.optional {
+			x.fail(name, opt.css, errors.New("element is not found"))
+		}
+		return
+	}
+
+	x.fillValue(target, fv, name, opt)
+}
+
+func (x *extractor) fillSlice(es ElementSelector, fv reflect.Value, name string, opt extractOption) {
+	if opt.css == "" {
+		x.fail(name, "", errors.New("css is required for slice field"))
+		return
+	}
+
+	var els ElementsTemplate
+	if err := catch(func() {
+		if es.Has(opt.css) {
+			els = es.Els(opt.css)
+		}
+	}); err != nil {
+		x.fail(name, opt.css, err)
+		return
+	}
+
+	if len(els) == 0 && !opt.optional {
+		x.fail(name, opt.css, errors.New("element is not found"))
+		return
+	}
+
+	itemOpt := opt
+	itemOpt.css = ""
+	itemOpt.optional = false
+
+	slice := reflect.MakeSlice(fv.Type(), len(els), len(els))
+	for idx, el := range els {
+		x.fillValue(el, slice.Index(idx), fmt.Sprintf("%s[%d]", name, idx), itemOpt)
+	}
+
+	fv.Set(slice)
+}
+
+func (x *extractor) fillValue(target ElementSelector, fv reflect.Value, name string, opt extractOption) {
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		before := len(x.errors)
+		x.fillValue(target, elem.Elem(), name, opt)
+		if len(x.errors) == before {
+			fv.Set(elem)
+		}
+		return
+	}
+
+	if fv.Kind() == reflect.Struct {
+		x.fillStruct(target, fv, name+".")
+		return
+	}
+
+	el, ok := target.(*ElementTemplate)
+	if !ok {
+		x.fail(name, opt.css, errors.New("css is required to read a value from a page"))
+		return
+	}
+
+	text, found, err := readElementValue(el, opt)
+	if err != nil {
+		x.fail(name, opt.css, err)
+		return
+	}
+
+	if !found {
+		if !opt.optional {
+			x.fail(name, opt.css, fmt.Errorf("attribute %s is not found", opt.attr))
+		}
+		return
+	}
+
+	if err = setParsed(fv, text, opt); err != nil {
+		x.fail(name, opt.css, err)
+	}
+}
+
+func (x *extractor) selectOne(es ElementSelector, css string) (ElementSelector, bool, error) {
+	if css == "" {
+		return es, true, nil
+	}
+
+	var el *ElementTemplate
+	err := catch(func() {
+		if es.Has(css) {
+			el = es.El(css)
+		}
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return el, el != nil, nil
+}
+
+func readElementValue(el *ElementTemplate, opt extractOption) (string, bool, error) {
+	var text string
+
+	switch {
+	case opt.attr != "":
+		attr, err := el.Attribute(opt.attr)
+		if err != nil {
+			return "", false, err
+		}
+		if attr == nil {
+			return "", false, nil
+		}
+		text = *attr
+	case opt.html:
+		html, err := el.HTML()
+		if err != nil {
+			return "", false, err
+		}
+		text = html
+	default:
+		t, err := el.Text()
+		if err != nil {
+			return "", false, err
+		}
+		text = t
+	}
+
+	return opt.stripAndTrim(text), true, nil
+}
+
+func (opt extractOption) stripAndTrim(text string) string {
+	if opt.strip != "" {
+		text = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(opt.strip, r) {
+				return -1
+			}
+			return r
+		}, text)
+	}
+
+	if !opt.notrim {
+		text = strings.TrimSpace(text)
+	}
+
+	return text
+}
+
+func setParsed(fv reflect.Value, text string, opt extractOption) error {
+	parse := opt.parse
+	if parse == "" {
+		parse = parseKindOf(fv.Kind())
+	}
+
+	switch parse {
+	case "string":
+		if fv.Kind() != reflect.String {
+			return fmt.Errorf("can not set string to %s", fv.Type())
+		}
+		fv.SetString(text)
+	case "int":
+		val, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			return err
+		}
+		return setNumber(fv, reflect.ValueOf(val))
+	case "uint":
+		val, err := strconv.ParseUint(text, 0, 64)
+		if err != nil {
+			return err
+		}
+		return setNumber(fv, reflect.ValueOf(val))
+	case "float":
+		val, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		return setNumber(fv, reflect.ValueOf(val))
+	case "bool":
+		val, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		if fv.Kind() != reflect.Bool {
+			return fmt.Errorf("can not set bool to %s", fv.Type())
+		}
+		fv.SetBool(val)
+	default:
+		return fmt.Errorf("unsupported parse %q for %s", parse, fv.Type())
+	}
+
+	return nil
+}
+
+func parseKindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	}
+
+	return kind.String()
+}
+
+// setNumber sets val of int64, uint64 or float64 to fv checking the range of the kind of fv
+func setNumber(fv reflect.Value, val reflect.Value) error {
+	overflow := fmt.Errorf("%v overflows %s", val.Interface(), fv.Type())
+
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch val.Kind() {
+		case reflect.Int64:
+			i = val.Int()
+		case reflect.Uint64:
+			if val.Uint() > math.MaxInt64 {
+				return overflow
+			}
+			i = int64(val.Uint())
+		case reflect.Float64:
+			f := val.Float()
+			if f != math.Trunc(f) {
+				return fmt.Errorf("%v has a fraction for %s", f, fv.Type())
+			}
+			if f < math.MinInt64 || f >= math.MaxInt64 {
+				return overflow
+			}
+			i = int64(f)
+		}
+		if fv.OverflowInt(i) {
+			return overflow
+		}
+		fv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch val.Kind() {
+		case reflect.Int64:
+			if val.Int() < 0 {
+				return overflow
+			}
+			u = uint64(val.Int())
+		case reflect.Uint64:
+			u = val.Uint()
+		case reflect.Float64:
+			f := val.Float()
+			if f != math.Trunc(f) {
+				return fmt.Errorf("%v has a fraction for %s", f, fv.Type())
+			}
+			if f < 0 || f >= math.MaxUint64 {
+				return overflow
+			}
+			u = uint64(f)
+		}
+		if fv.OverflowUint(u) {
+			return overflow
+		}
+		fv.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f := val.Convert(reflect.TypeOf(float64(0))).Float()
+		if fv.OverflowFloat(f) {
+			return overflow
+		}
+		fv.SetFloat(f)
+		return nil
+	case reflect.String:
+		fv.SetString(fmt.Sprint(val.Interface()))
+		return nil
+	}
+
+	return fmt.Errorf("can not set %s to %s", val.Type(), fv.Type())
+}
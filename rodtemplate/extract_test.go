@@ -0,0 +1,109 @@
+package rodtemplate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExtractTag(t *testing.T) {
+	for tag, expect := range map[string]extractOption{
+		"css=.price,parse=uint,strip=,": {css: ".price", parse: "uint", strip: ","},
+		"css=a,attr=href":               {css: "a", attr: "href"},
+		"optional,css=h1, h2":           {css: "h1, h2", optional: true},
+		"css=.name,html,notrim":         {css: ".name", html: true, notrim: true},
+	} {
+		opt, err := parseExtractTag(tag)
+		if err != nil {
+			t.Errorf("Expecting no error for %s, got %v", tag, err)
+		}
+
+		if opt != expect {
+			t.Errorf("Expecting %+v for %s, got %+v", expect, tag, opt)
+		}
+	}
+
+	if _, err := parseExtractTag("selector=a"); err == nil {
+		t.Error("Expecting error for unknown key")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	_, _, pt := openFixture(t, "table.html")
+
+	var product struct {
+		Name     string   `rod:"css=.name"`
+		Price    uint64   `rod:"css=.price,strip=,"`
+		Link     string   `rod:"css=.link,attr=href"`
+		Tags     []string `rod:"css=.tags li"`
+		Discount *int     `rod:"css=.discount,optional"`
+		Stock    int      `rod:"css=.stock"`
+		Title    int      `rod:"css=.name"`
+	}
+
+	err := pt.El("#product").Extract(&product)
+
+	extractErr, ok := err.(*ExtractError)
+	if !ok || len(extractErr.Fields) != 2 {
+		t.Fatalf("Expecting errors of Stock and Title, got %v", err)
+	}
+
+	if extractErr.Fields[0].Field != "Stock" || extractErr.Fields[1].Field != "Title" {
+		t.Errorf("Expecting errors of Stock and Title, got %v", err)
+	}
+
+	if product.Name != "Apple" || product.Price != 1200 || product.Link != "/products/apple" {
+		t.Errorf("Expecting extracted product, got %+v", product)
+	}
+
+	if len(product.Tags) != 2 || product.Discount != nil {
+		t.Errorf("Expecting tags and no discount, got %+v", product)
+	}
+}
+
+func TestSetParsedNumber(t *testing.T) {
+	var target struct {
+		F32 float32
+		I32 float32
+		F64 float64
+		I8  int8
+		U   uint
+	}
+	v := reflect.ValueOf(&target).Elem()
+
+	for _, c := range []struct {
+		field, text, parse string
+	}{
+		{"F32", "1.1", "float"},
+		{"I32", "-3", "int"},
+		{"F64", "2.5", "float"},
+		{"I8", "-128", "int"},
+		{"I8", "12", "float"},
+		{"U", "7", "int"},
+	} {
+		if err := setParsed(v.FieldByName(c.field), c.text, extractOption{parse: c.parse}); err != nil {
+			t.Errorf("Expecting no error for %s of %s, got %v", c.text, c.field, err)
+		}
+	}
+
+	if target.F32 != 1.1 || target.I32 != -3 {
+		t.Errorf("Expecting 1.1 and -3 as float32, got %v and %v", target.F32, target.I32)
+	}
+	if target.F64 != 2.5 || target.I8 != 12 || target.U != 7 {
+		t.Errorf("Expecting parsed numbers, got %+v", target)
+	}
+
+	for _, c := range []struct {
+		field, text, parse, msg string
+	}{
+		{"F32", "1e39", "float", "overflows"},
+		{"I8", "128", "int", "overflows"},
+		{"I8", "1.5", "float", "fraction"},
+		{"U", "-1", "int", "overflows"},
+	} {
+		err := setParsed(v.FieldByName(c.field), c.text, extractOption{parse: c.parse})
+		if err == nil || !strings.Contains(err.Error(), c.msg) {
+			t.Errorf("Expecting error of %s for %s of %s, got %v", c.msg, c.text, c.field, err)
+		}
+	}
+}
@@ -0,0 +1,581 @@
+package rodtemplate
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+const harVersion = "1.2"
+
+// HAR is the root of a HTTP Archive 1.2 document
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Pages   []HARPage   `json:"pages"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+}
+
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type HAREntry struct {
+	Pageref         string      `json:"pageref,omitempty"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+// clone returns a deep copy of e
+func (e *HAREntry) clone() *HAREntry {
+	c := *e
+
+	c.Request.Cookies = append([]HARCookie{}, e.Request.Cookies...)
+	c.Request.Headers = append([]HARNameValue{}, e.Request.Headers...)
+	c.Request.QueryString = append([]HARNameValue{}, e.Request.QueryString...)
+	if e.Request.PostData != nil {
+		postData := *e.Request.PostData
+		c.Request.PostData = &postData
+	}
+
+	c.Response.Cookies = append([]HARCookie{}, e.Response.Cookies...)
+	c.Response.Headers = append([]HARNameValue{}, e.Response.Headers...)
+
+	return &c
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are in milliseconds, -1 means the phase does not apply
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+type HAROption struct {
+	// WithBody captures response bodies (and post data missing from the request event)
+	WithBody bool
+	// MaxBodySize drops captured bodies larger than this, 0 means no limit
+	MaxBodySize int
+}
+
+// HARRecorder records network traffic of a page until Stop is called
+type HARRecorder struct {
+	opt  HAROption
+	page *rod.Page
+
+	mu       sync.Mutex
+	pending  map[proto.NetworkRequestID]*harPending
+	entries  []*harPending
+	started  time.Time
+	pageID   string
+	title    string
+	stop     func()
+	finished chan struct{}
+}
+
+type harPending struct {
+	entry     *HAREntry
+	timestamp proto.MonotonicTime
+	timing    *proto.NetworkResourceTiming
+}
+
+// RecordHAR starts recording network traffic of the page
+func (p *PageTemplate) RecordHAR(opt HAROption) *HARRecorder {
+	r := &HARRecorder{
+		opt:      opt,
+		page:     p.P,
+		pending:  map[proto.NetworkRequestID]*harPending{},
+		started:  time.Now(),
+		pageID:   string(p.P.FrameID),
+		finished: make(chan struct{}),
+	}
+
+	page, cancel := p.P.WithCancel()
+	r.stop = cancel
+
+	wait := page.EachEvent(
+		r.onRequestWillBeSent,
+		r.onResponseReceived,
+		r.onLoadingFinished,
+		r.onLoadingFailed,
+	)
+
+	go func() {
+		defer close(r.finished)
+		wait()
+	}()
+
+	return r
+}
+
+// Stop stops recording, requests still in flight are kept without response
+func (r *HARRecorder) Stop() {
+	r.stop()
+	<-r.finished
+
+	if info, err := r.page.Info(); err == nil {
+		r.mu.Lock()
+		r.title = info.Title
+		r.mu.Unlock()
+	}
+}
+
+// HAR returns a snapshot of the recorded traffic masking secrets known to credential.Secrets when they are recorded
+// or now, like a password posted by a login form
+func (r *HARRecorder) HAR() *HAR {
+	return redactHAR(r.har())
+}
+
+func (r *HARRecorder) har() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// entries are copied as pending ones are still updated by events
+	entries := make([]*HAREntry, 0, len(r.entries)+len(r.pending))
+	for _, p := range r.entries {
+		entries = append(entries, p.entry.clone())
+	}
+	for _, p := range r.pending {
+		entries = append(entries, p.entry.clone())
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	return &HAR{
+		Log: HARLog{
+			Version: harVersion,
+			Creator: HARCreator{Name: "github.com/darimuri/go-lib/rodtemplate"},
+			Pages: []HARPage{{
+				StartedDateTime: r.started,
+				ID:              r.pageID,
+				Title:           r.title,
+				PageTimings:     HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+			}},
+			Entries: entries,
+		},
+	}
+}
+
+// WriteTo writes the recorded traffic as HAR json
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// Save writes the recorded traffic as HAR json to harPath
+func (r *HARRecorder) Save(harPath string) error {
+	f, err := os.Create(harPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (r *HARRecorder) onRequestWillBeSent(e *proto.NetworkRequestWillBeSent) {
+	req := e.Request
+
+	// post data is fetched outside the lock as it's a round trip to the browser
+	postData := req.PostData
+	if req.HasPostData && postData == "" && r.opt.WithBody {
+		if res, err := (proto.NetworkGetRequestPostData{RequestID: e.RequestID}).Call(r.page); err == nil {
+			postData = res.PostData
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// same request id is reused for redirects
+	if prev, ok := r.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+		r.applyResponse(prev, e.RedirectResponse)
+		prev.entry.Response.RedirectURL = e.Request.URL
+		r.finish(prev, e.Timestamp)
+		delete(r.pending, e.RequestID)
+		r.entries = append(r.entries, prev)
+	}
+
+	entry := &HAREntry{
+		Pageref:         r.pageID,
+		StartedDateTime: e.WallTime.Time(),
+		Time:            0,
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         req.URL + req.URLFragment,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARCookie{},
+			Headers:     harHeaders(req.Headers),
+			QueryString: harQueryString(req.URL),
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: HARTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 0, Receive: 0, SSL: -1},
+	}
+
+	if req.HasPostData {
+		entry.Request.PostData = &HARPostData{
+			MimeType: harHeader(req.Headers, "Content-Type"),
+			Text:     postData,
+		}
+		entry.Request.BodySize = len(postData)
+	}
+
+	// secrets are masked as they are recorded too, a password of a login is registered only while submitting
+	r.pending[e.RequestID] = &harPending{entry: redactEntry(entry), timestamp: e.Timestamp}
+}
+
+func (r *HARRecorder) onResponseReceived(e *proto.NetworkResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[e.RequestID]
+	if !ok {
+		return
+	}
+
+	r.applyResponse(p, e.Response)
+}
+
+func (r *HARRecorder) onLoadingFinished(e *proto.NetworkLoadingFinished) {
+	r.mu.Lock()
+	p, ok := r.pending[e.RequestID]
+	if ok {
+		delete(r.pending, e.RequestID)
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// body is fetched outside the lock as it's a round trip to the browser
+	var content HARContent
+	if r.opt.WithBody {
+		if res, err := (proto.NetworkGetResponseBody{RequestID: e.RequestID}).Call(r.page); err == nil {
+			if r.opt.MaxBodySize <= 0 || len(res.Body) <= r.opt.MaxBodySize {
+				content.Text = res.Body
+				if res.Base64Encoded {
+					content.Encoding = "base64"
+				}
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.entry.Response.BodySize = int(e.EncodedDataLength)
+	p.entry.Response.Content.Size = int(e.EncodedDataLength)
+	p.entry.Response.Content.Text = content.Text
+	p.entry.Response.Content.Encoding = content.Encoding
+
+	r.finish(p, e.Timestamp)
+	r.entries = append(r.entries, p)
+}
+
+func (r *HARRecorder) onLoadingFailed(e *proto.NetworkLoadingFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[e.RequestID]
+	if !ok {
+		return
+	}
+	delete(r.pending, e.RequestID)
+
+	p.entry.Comment = e.ErrorText
+	r.finish(p, e.Timestamp)
+	r.entries = append(r.entries, p)
+}
+
+func (r *HARRecorder) applyResponse(p *harPending, res *proto.NetworkResponse) {
+	entry := p.entry
+
+	entry.Response.Status = res.Status
+	entry.Response.StatusText = res.StatusText
+	entry.Response.HTTPVersion = harHTTPVersion(res.Protocol)
+	entry.Response.Headers = harHeaders(res.Headers)
+	entry.Response.Content.MimeType = res.MIMEType
+	entry.Response.RedirectURL = harHeader(res.Headers, "Location")
+	entry.Request.HTTPVersion = entry.Response.HTTPVersion
+	entry.ServerIPAddress = res.RemoteIPAddress
+
+	if len(res.RequestHeaders) > 0 {
+		entry.Request.Headers = harHeaders(res.RequestHeaders)
+	}
+
+	if res.ConnectionID != 0 {
+		entry.Connection = strconv.FormatFloat(res.ConnectionID, 'f', -1, 64)
+	}
+
+	p.timing = res.Timing
+}
+
+func (r *HARRecorder) finish(p *harPending, end proto.MonotonicTime) {
+	redactEntry(p.entry)
+
+	total := float64(end-p.timestamp) * 1000
+	t := p.timing
+
+	if t == nil {
+		p.entry.Time = total
+		p.entry.Timings.Receive = total
+		return
+	}
+
+	timings := HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	// offsets in timing are milliseconds relative to RequestTime
+	blockedEnd := firstNonNegative(t.DNSStart, t.ConnectStart, t.SendStart)
+	if blockedEnd > 0 {
+		timings.Blocked = blockedEnd
+	}
+	if t.DNSStart >= 0 {
+		timings.DNS = t.DNSEnd - t.DNSStart
+	}
+	if t.ConnectStart >= 0 {
+		timings.Connect = t.ConnectEnd - t.ConnectStart
+	}
+	if t.SslStart >= 0 {
+		timings.SSL = t.SslEnd - t.SslStart
+	}
+	timings.Send = t.SendEnd - t.SendStart
+	timings.Wait = t.ReceiveHeadersEnd - t.SendEnd
+	timings.Receive = (float64(end)-t.RequestTime)*1000 - t.ReceiveHeadersEnd
+	if timings.Receive < 0 {
+		timings.Receive = 0
+	}
+
+	p.entry.Timings = timings
+	p.entry.Time = 0
+	for _, v := range []float64{timings.Blocked, timings.DNS, timings.Connect, timings.Send, timings.Wait, timings.Receive} {
+		if v > 0 {
+			p.entry.Time += v
+		}
+	}
+}
+
+func firstNonNegative(values ...float64) float64 {
+	for _, v := range values {
+		if v >= 0 {
+			return v
+		}
+	}
+
+	return -1
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2.0"
+	case "h3":
+		return "HTTP/3.0"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "":
+		return "HTTP/1.1"
+	default:
+		return strings.ToUpper(protocol)
+	}
+}
+
+func harHeaders(headers proto.NetworkHeaders) []HARNameValue {
+	nvs := make([]HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		// multiple values of a header are joined by new line
+		for _, v := range strings.Split(value.Str(), "\n") {
+			nvs = append(nvs, HARNameValue{Name: name, Value: v})
+		}
+	}
+
+	sort.SliceStable(nvs, func(i, j int) bool {
+		return nvs[i].Name < nvs[j].Name
+	})
+
+	return nvs
+}
+
+func harHeader(headers proto.NetworkHeaders, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v.Str()
+		}
+	}
+
+	return ""
+}
+
+func harQueryString(rawURL string) []HARNameValue {
+	nvs := []HARNameValue{}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nvs
+	}
+
+	for name, values := range u.Query() {
+		for _, v := range values {
+			nvs = append(nvs, HARNameValue{Name: name, Value: v})
+		}
+	}
+
+	sort.SliceStable(nvs, func(i, j int) bool {
+		return nvs[i].Name < nvs[j].Name
+	})
+
+	return nvs
+}
+
+// redactHAR returns a copy of h masking secrets in urls, headers, query strings, cookies, post data and contents
+func redactHAR(h *HAR) *HAR {
+	result := &HAR{Log: h.Log}
+	result.Log.Pages = append([]HARPage{}, h.Log.Pages...)
+	for i := range result.Log.Pages {
+		result.Log.Pages[i].Title = credential.Redact(result.Log.Pages[i].Title)
+	}
+
+	result.Log.Entries = make([]*HAREntry, 0, len(h.Log.Entries))
+	for _, e := range h.Log.Entries {
+		result.Log.Entries = append(result.Log.Entries, redactEntry(e.clone()))
+	}
+
+	return result
+}
+
+// redactEntry masks secrets in e in place
+func redactEntry(e *HAREntry) *HAREntry {
+	req, res := &e.Request, &e.Response
+
+	req.URL = credential.Redact(req.URL)
+	redactNameValues(req.Headers)
+	redactNameValues(req.QueryString)
+	redactCookies(req.Cookies)
+	if req.PostData != nil {
+		req.PostData.Text = credential.Redact(req.PostData.Text)
+	}
+
+	redactNameValues(res.Headers)
+	redactCookies(res.Cookies)
+	res.RedirectURL = credential.Redact(res.RedirectURL)
+	res.Content.Text = credential.Redact(res.Content.Text)
+
+	e.Comment = credential.Redact(e.Comment)
+
+	return e
+}
+
+func redactNameValues(nvs []HARNameValue) {
+	for i := range nvs {
+		nvs[i].Value = credential.Redact(nvs[i].Value)
+	}
+}
+
+func redactCookies(cookies []HARCookie) {
+	for i := range cookies {
+		cookies[i].Value = credential.Redact(cookies[i].Value)
+	}
+}
@@ -0,0 +1,194 @@
+package rodtemplate
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+// Logger is a leveled logger taking alternating key, value args like log/slog.
+// *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NopLogger discards every record, it is the default logger
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+var defaultLogger = NopLogger
+
+// SetDefaultLogger replaces the logger of templates not having one and returns a func restoring the previous one
+func SetDefaultLogger(l Logger) (restore func()) {
+	prev := defaultLogger
+	defaultLogger = orNop(l)
+
+	return func() {
+		defaultLogger = prev
+	}
+}
+
+func orNop(l Logger) Logger {
+	if l == nil {
+		return NopLogger
+	}
+
+	return l
+}
+
+// redacting wraps l to mask secrets known to credential.Secrets in messages and values
+func redacting(l Logger) Logger {
+	if l == NopLogger {
+		return l
+	}
+
+	if _, ok := l.(redactLogger); ok {
+		return l
+	}
+
+	return redactLogger{l: l}
+}
+
+type redactLogger struct {
+	l Logger
+}
+
+func (r redactLogger) Debug(msg string, args ...any) {
+	r.l.Debug(credential.Redact(msg), redactArgs(args)...)
+}
+
+func (r redactLogger) Info(msg string, args ...any) {
+	r.l.Info(credential.Redact(msg), redactArgs(args)...)
+}
+
+func (r redactLogger) Warn(msg string, args ...any) {
+	r.l.Warn(credential.Redact(msg), redactArgs(args)...)
+}
+
+func (r redactLogger) Error(msg string, args ...any) {
+	r.l.Error(credential.Redact(msg), redactArgs(args)...)
+}
+
+func redactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			redacted[i] = credential.Redact(v)
+		case error:
+			redacted[i] = credential.RedactError(v)
+		case fmt.Stringer:
+			redacted[i] = arg
+			if s := v.String(); credential.Redact(s) != s {
+				redacted[i] = credential.Redact(s)
+			}
+		default:
+			redacted[i] = arg
+		}
+	}
+
+	return redacted
+}
+
+// Level orders records like slog levels
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	}
+
+	return "ERROR"
+}
+
+// NewStdLogger writes records at or above level to l as "LEVEL msg key=value" lines, log.Default() if l is nil
+func NewStdLogger(l *log.Logger, level Level) Logger {
+	if l == nil {
+		l = log.Default()
+	}
+
+	return &stdLogger{l: l, level: level}
+}
+
+type stdLogger struct {
+	l     *log.Logger
+	level Level
+}
+
+func (s *stdLogger) Debug(msg string, args ...any) {
+	s.log(LevelDebug, msg, args)
+}
+
+func (s *stdLogger) Info(msg string, args ...any) {
+	s.log(LevelInfo, msg, args)
+}
+
+func (s *stdLogger) Warn(msg string, args ...any) {
+	s.log(LevelWarn, msg, args)
+}
+
+func (s *stdLogger) Error(msg string, args ...any) {
+	s.log(LevelError, msg, args)
+}
+
+func (s *stdLogger) log(level Level, msg string, args []any) {
+	if level < s.level {
+		return
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(level.String())
+	sb.WriteString(" ")
+	sb.WriteString(msg)
+
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(sb, " !BADKEY=%v", args[i])
+			break
+		}
+		fmt.Fprintf(sb, " %v=%v", args[i], quoteSpaced(args[i+1]))
+	}
+
+	s.l.Println(sb.String())
+}
+
+func quoteSpaced(v any) any {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	default:
+		return v
+	}
+
+	if strings.ContainsAny(s, " =\"") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
@@ -0,0 +1,118 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+// recordLogger keeps records as "LEVEL msg key=value" lines
+type recordLogger struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (r *recordLogger) record(level Level, msg string, args []any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := level.String() + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		line += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	r.records = append(r.records, line)
+}
+
+func (r *recordLogger) Debug(msg string, args ...any) { r.record(LevelDebug, msg, args) }
+func (r *recordLogger) Info(msg string, args ...any)  { r.record(LevelInfo, msg, args) }
+func (r *recordLogger) Warn(msg string, args ...any)  { r.record(LevelWarn, msg, args) }
+func (r *recordLogger) Error(msg string, args ...any) { r.record(LevelError, msg, args) }
+
+func TestStdLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewStdLogger(log.New(buf, "", 0), LevelInfo)
+
+	l.Debug("hidden")
+	l.Info("go to login gate", "url", "http://localhost", "attempt", 2)
+	l.Warn("failed", "error", fmt.Errorf("not found"), "dangling")
+
+	expected := "INFO go to login gate url=http://localhost attempt=2\n" +
+		"WARN failed error=\"not found\" !BADKEY=dangling\n"
+	if buf.String() != expected {
+		t.Errorf("Expecting %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWaitForLogger(t *testing.T) {
+	r := &recordLogger{}
+	defer SetDefaultLogger(r)()
+
+	count := 0
+	err := WaitFor("target", time.Second, time.Millisecond, func() bool {
+		count++
+		return count == 3
+	}, func() {})
+	if err != nil {
+		t.Fatalf("Expecting no error, got %v", err)
+	}
+
+	if len(r.records) != 2 || !strings.HasPrefix(r.records[1], "DEBUG retry after sleep kind=WaitFor target=target attempt=2") {
+		t.Errorf("Expecting retries logged with attempt, got %v", r.records)
+	}
+}
+
+func TestSetDefaultLogger(t *testing.T) {
+	r := &recordLogger{}
+	restore := SetDefaultLogger(r)
+
+	pt := &PageTemplate{}
+	if pt.logger() != (redactLogger{l: r}) {
+		t.Error("Expecting default logger for page without logger")
+	}
+
+	own := &recordLogger{}
+	if pt.derive(nil).logger() != (redactLogger{l: r}) || (&PageTemplate{Logger: own}).derive(nil).logger() != (redactLogger{l: own}) {
+		t.Error("Expecting derived page sharing logger")
+	}
+
+	restore()
+
+	if pt.logger() != NopLogger {
+		t.Errorf("Expecting NopLogger restored, got %v", pt.logger())
+	}
+}
+
+func TestRedactingLogger(t *testing.T) {
+	credential.Secrets.Add("logged-secret")
+	defer credential.Secrets.Remove("logged-secret")
+
+	r := &recordLogger{}
+	l := redacting(r)
+
+	if redacting(l) != l || redacting(NopLogger) != NopLogger {
+		t.Error("Expecting no double wrapping")
+	}
+
+	l.Info("typed logged-secret", "value", "logged-secret", "error", errors.New("bad logged-secret"), "elapsed", time.Second)
+
+	expected := "INFO typed [REDACTED] value=[REDACTED] error=bad [REDACTED] elapsed=1s"
+	if len(r.records) != 1 || r.records[0] != expected {
+		t.Errorf("Expecting %s, got %v", expected, r.records)
+	}
+}
+
+func TestWrapSharesLogger(t *testing.T) {
+	own := &recordLogger{}
+	pt := &PageTemplate{Logger: own}
+
+	if el := pt.Wrap(nil); el.owner != pt || el.owner.derive(nil).logger() != (redactLogger{l: own}) {
+		t.Errorf("Expecting wrapped element sharing the logger of the page, got %+v", el)
+	}
+}
@@ -1,9 +1,7 @@
 package rodtemplate
 
 import (
-	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path"
 	"strings"
@@ -11,30 +9,70 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
+
+	"github.com/darimuri/go-lib/credential"
 )
 
+// ErrLoginFailed is returned when login is not succeeded with ID, Password, it is credential.ErrRejected
+var ErrLoginFailed error = loginFailedError{}
+
+type loginFailedError struct{}
+
+func (loginFailedError) Error() string {
+	return "failed to login"
+}
+
+func (loginFailedError) Is(target error) bool {
+	return target == credential.ErrRejected
+}
+
 type Login struct {
 	*PageTemplate
 	Handler LoginHandler
+
+	// Credential tells which sources supplied ID and Password after Validate
+	Credential *credential.Resolved
 }
 
+// Validate resolves ID and Password from the handler, os environment variables
+// with names of EnvID and EnvPassword, then CredentialSources in order.
+// Password is registered to credential.Secrets until credential.Secrets.Remove, BrowserTemplate.Login removes it
+// after submitting.
 func (l *Login) Validate() error {
-	if l.Handler.ID == "" {
-		l.Handler.ID = os.Getenv(l.Handler.EnvID)
+	sources := []credential.Source{
+		credential.Explicit(l.Handler.ID, l.Handler.Password),
+		credential.Env(l.Handler.EnvID, l.Handler.EnvPassword),
 	}
+	sources = append(sources, l.Handler.CredentialSources...)
 
-	if l.Handler.Password == "" {
-		l.Handler.Password = os.Getenv(l.Handler.EnvPassword)
+	resolved, err := credential.NewResolver(sources...).Resolve()
+	if err != nil {
+		return fmt.Errorf("id, password is required: %w", err)
 	}
 
-	if l.Handler.ID == "" || l.Handler.Password == "" {
-		return fmt.Errorf("id, password is required as parameter or os environment variables with names(%s, %s)", l.Handler.EnvID, l.Handler.EnvPassword)
-	}
+	l.logger().Info("resolved credential", "id_source", resolved.IDSource, "password_source", resolved.SecretSource)
+
+	l.Handler.ID = resolved.ID
+	l.Handler.Password = resolved.Secret
+	l.Credential = resolved
 
 	return nil
 }
 
-func (l *Login) Submit(b *rod.Browser) error {
+// Submit types ID, Password into the login page found in the page, its iframes or other pages of b.
+// Secrets known to credential.Secrets are masked in the returned error.
+func (l *Login) Submit(b *rod.Browser) (err error) {
+	span := l.PageTemplate.startSpan("rodtemplate.Login.Submit", AttrSelector.String(l.Handler.LoginInputSelector))
+	defer endSpan(span, &err)
+
+	// the password is masked while submitting, in traffic recorded by RecordHAR for example, and in the error
+	credential.Secrets.Add(l.Handler.Password)
+	defer credential.Secrets.Remove(l.Handler.Password)
+
+	return credential.RedactError(l.submit(b))
+}
+
+func (l *Login) submit(b *rod.Browser) error {
 	h := l.Handler
 	pt := l.PageTemplate
 
@@ -42,94 +80,25 @@ func (l *Login) Submit(b *rod.Browser) error {
 
 	pt.WaitIdle()
 
-	log.Println("find login page")
-
-	for i := 0; i < 10; i++ {
-		//find login input selector in iframes
-		if pt.Has("iframe") {
-			for _, e := range pt.Els("iframe") {
-				iFrame, err := e.Frame()
-				if err != nil {
-					continue
-				}
+	logger := pt.logger()
 
-				//to prevent nil pointer reference
-				if srcAttr, errAttr := e.Attribute("src"); errAttr != nil {
-					log.Println("failed to get iframe src attribute", errAttr)
-				} else if srcAttr == nil {
-					continue
-				} else if strings.HasPrefix(*srcAttr, "http") {
-					sameUrl, errSameUrl := IsSameDomainUrl(pt.URL(), *srcAttr)
-					if errSameUrl != nil {
-						log.Println("failed to parse url", pt.URL(), "error", errSameUrl.Error())
-					}
-					if !sameUrl {
-						log.Println("prevent nil pointer reference on iframe url(", *srcAttr, ")outside owner url domain", pt.URL())
-						continue
-					}
-				}
+	logger.Debug("find login page", "url", pt.URL(), "selector", h.LoginInputSelector)
 
-				if has, _, errHas := iFrame.Has("body"); !has || errHas != nil {
-					continue
-				}
+	attempt := 0
+	found := func() (bool, error) {
+		attempt++
 
-				_, err = iFrame.Element("body")
-				if err != nil {
-					errMessage := err.Error()
-					if strings.Contains(errMessage, "Frame with the given id was not found.") {
-						continue
-					} else {
-						return err
-					}
-				}
+		var errFind error
+		loginPt, errFind = l.findLoginPage(b, attempt)
 
-				myPt := &PageTemplate{P: iFrame}
-				//myPt.WaitRequestIdle()
-
-				if myPt.Has(h.LoginInputSelector) {
-					loginPt = myPt
-					log.Println("found LoginInputSelector", h.LoginLinkSelector, "in iframes")
-					break
-				}
-			}
-		}
-
-		//find login input selector in another windows
-		if loginPt == nil {
-			for _, p := range b.MustPages() {
-				if p.FrameID == pt.FrameID() {
-					continue
-				}
-
-				myPt := &PageTemplate{P: p}
-
-				if myPt.Has(h.LoginInputSelector) {
-					loginPt = myPt
-					log.Println("found LoginInputSelector", h.LoginLinkSelector, "in pages")
-					break
-				}
-			}
-		}
-
-		//find login input selector in page
-		if loginPt == nil {
-			if pt.Has(h.LoginInputSelector) {
-				loginPt = pt
-				log.Println("found LoginInputSelector", h.LoginLinkSelector, "in current page")
-				break
-			}
-
-		}
-
-		if loginPt != nil {
-			break
-		}
-
-		time.Sleep(100 * time.Millisecond)
+		return loginPt != nil, errFind
 	}
 
-	if loginPt == nil {
-		return errors.New("failed to find login input selector " + h.LoginInputSelector)
+	if _, err := pt.retry(pt.context(), WaitKindLoginInput, h.LoginInputSelector, LoginInputPolicy, found, nil); err != nil {
+		if isTimeout(err) {
+			return fmt.Errorf("failed to find login input selector %s: %w", h.LoginInputSelector, err)
+		}
+		return err
 	}
 
 	loginPageURL := pt.URL()
@@ -169,29 +138,28 @@ func (l *Login) Submit(b *rod.Browser) error {
 		}
 
 		if !success {
-			return fmt.Errorf("login failed for LoginSuccessCheckHandler returned success failed")
+			return fmt.Errorf("%w for LoginSuccessCheckHandler returned success failed", ErrLoginFailed)
 		}
 
 		return nil
 	}
 
-	for i := 0; i < 100; i++ {
+	loggedIn := func() (bool, error) {
 		if !pt.Has(h.LoginSuccessSelector) {
-			time.Sleep(time.Millisecond * 100)
-			continue
+			return false, nil
 		}
 
-		if str, err := pt.El(h.LoginSuccessSelector).Text(); err != nil {
-			if IsObjectNotFoundError(err) {
-				continue
-			} else {
-				return err
-			}
-		} else if str != "" {
-			break
+		str, err := pt.El(h.LoginSuccessSelector).Text()
+		if err != nil && IsObjectNotFoundError(err) {
+			return false, nil
 		}
 
-		time.Sleep(time.Millisecond * 100)
+		return str != "", err
+	}
+
+	// login failure is told by the url below rather than the timeout
+	if _, err := pt.retry(pt.context(), WaitKindLoginSuccess, h.LoginSuccessSelector, LoginSuccessPolicy, loggedIn, nil); err != nil && !isTimeout(err) {
+		return err
 	}
 
 	currentPageURL := pt.URL()
@@ -203,19 +171,24 @@ func (l *Login) Submit(b *rod.Browser) error {
 			if _, err := os.Stat(screenshotPath); err != nil {
 				if os.IsNotExist(err) {
 					if err = os.MkdirAll(screenshotPath, 0755); err != nil {
-						log.Println(err)
-						return errors.New("failed to login")
+						logger.Warn("failed to create screenshot directory", "path", screenshotPath, "error", err)
+						return ErrLoginFailed
 					}
 				} else {
-					log.Println(err)
-					return errors.New("failed to login")
+					logger.Warn("failed to stat screenshot directory", "path", screenshotPath, "error", err)
+					return ErrLoginFailed
 				}
 			}
 
-			screenshotFile := fmt.Sprintf("loginfailed.%s.png", time.Now().Format("20060102150405"))
-			pt.ScreenShot(pt.El("html"), path.Join(screenshotPath, screenshotFile), 0)
+			dumpName := fmt.Sprintf("loginfailed.%s", time.Now().Format("20060102150405"))
+			if err := pt.saveScreenShot(path.Join(screenshotPath, dumpName+".png")); err != nil {
+				logger.Warn("failed to save screenshot", "path", screenshotPath, "error", err)
+			}
+			if err := pt.DumpHTML(path.Join(screenshotPath, dumpName+".html")); err != nil {
+				logger.Warn("failed to dump html", "path", screenshotPath, "error", err)
+			}
 		}
-		return errors.New("failed to login")
+		return ErrLoginFailed
 	}
 
 	if h.LoginAfterURL != "" {
@@ -226,3 +199,89 @@ func (l *Login) Submit(b *rod.Browser) error {
 
 	return nil
 }
+
+// findLoginPage returns the page or frame having LoginInputSelector among iframes of the page,
+// other pages of b and the page itself, nil if not found
+func (l *Login) findLoginPage(b *rod.Browser, attempt int) (*PageTemplate, error) {
+	h := l.Handler
+	pt := l.PageTemplate
+	logger := pt.logger()
+
+	var loginPt *PageTemplate
+
+	//find login input selector in iframes
+	if pt.Has("iframe") {
+		for _, e := range pt.Els("iframe") {
+			iFrame, err := e.Frame()
+			if err != nil {
+				continue
+			}
+
+			//to prevent nil pointer reference
+			if srcAttr, errAttr := e.Attribute("src"); errAttr != nil {
+				logger.Warn("failed to get iframe src attribute", "frame_id", pt.FrameID(), "error", errAttr)
+			} else if srcAttr == nil {
+				continue
+			} else if strings.HasPrefix(*srcAttr, "http") {
+				sameUrl, errSameUrl := IsSameDomainUrl(pt.URL(), *srcAttr)
+				if errSameUrl != nil {
+					logger.Warn("failed to parse url", "url", pt.URL(), "error", errSameUrl)
+				}
+				if !sameUrl {
+					logger.Debug("skip iframe outside owner url domain to prevent nil pointer reference", "src", *srcAttr, "url", pt.URL())
+					continue
+				}
+			}
+
+			if has, _, errHas := iFrame.Has("body"); !has || errHas != nil {
+				continue
+			}
+
+			_, err = iFrame.Element("body")
+			if err != nil {
+				errMessage := err.Error()
+				if strings.Contains(errMessage, "Frame with the given id was not found.") {
+					continue
+				} else {
+					return nil, err
+				}
+			}
+
+			myPt := pt.derive(iFrame)
+			//myPt.WaitRequestIdle()
+
+			if myPt.Has(h.LoginInputSelector) {
+				loginPt = myPt
+				logger.Info("found login input in iframe", "selector", h.LoginInputSelector, "frame_id", myPt.FrameID(), "attempt", attempt)
+				break
+			}
+		}
+	}
+
+	//find login input selector in another windows
+	if loginPt == nil {
+		for _, p := range b.MustPages() {
+			if p.FrameID == pt.FrameID() {
+				continue
+			}
+
+			myPt := pt.derive(p)
+
+			if myPt.Has(h.LoginInputSelector) {
+				loginPt = myPt
+				logger.Info("found login input in another page", "selector", h.LoginInputSelector, "url", myPt.URL(), "attempt", attempt)
+				break
+			}
+		}
+	}
+
+	//find login input selector in page
+	if loginPt == nil {
+		if pt.Has(h.LoginInputSelector) {
+			loginPt = pt
+			logger.Info("found login input in current page", "selector", h.LoginInputSelector, "url", pt.URL(), "attempt", attempt)
+		}
+	}
+
+	return loginPt, nil
+}
@@ -0,0 +1,143 @@
+package rodtemplate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/darimuri/go-lib/credential"
+	"github.com/darimuri/go-lib/rodtemplate/rodtest"
+)
+
+func testLoginHandler(s *rodtest.Server) LoginHandler {
+	return LoginHandler{
+		LoginGateURL:          s.URLOf("gate.html"),
+		LoginLinkSelector:     ".login-link",
+		LoginInputSelector:    "#id",
+		PasswordInputSelector: "#password",
+		LoginSuccessSelector:  ".welcome",
+		ID:                    s.ID,
+		Password:              s.Password,
+	}
+}
+
+func TestLoginSubmit(t *testing.T) {
+	s, b := rodtest.Setup(t)
+
+	pt, err := NewBrowserTemplate(b).Login(testLoginHandler(s))
+	if err != nil {
+		t.Fatalf("Expecting login success, got %v", err)
+	}
+
+	if !strings.HasSuffix(pt.URL(), "/home") {
+		t.Errorf("Expecting home after login, got %s", pt.URL())
+	}
+}
+
+func TestLoginThenRecordHAR(t *testing.T) {
+	s, b := rodtest.Setup(t)
+
+	pt, err := NewBrowserTemplate(b).Login(testLoginHandler(s))
+	if err != nil {
+		t.Fatalf("Expecting login success, got %v", err)
+	}
+
+	if credential.Redact(s.Password) != s.Password {
+		t.Errorf("Expecting password of login not masked after login, got %s", credential.Redact(s.Password))
+	}
+
+	r := pt.RecordHAR(HAROption{WithBody: true})
+	if err = pt.Navigate(s.URLOf("login.html")); err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+
+	for _, e := range r.HAR().Log.Entries {
+		if e.Request.URL == s.URLOf("login.html") && !strings.Contains(e.Response.Content.Text, `type="password"`) {
+			t.Errorf("Expecting login page body unmasked, got %q", e.Response.Content.Text)
+		}
+	}
+}
+
+func TestLoginSubmitInIframe(t *testing.T) {
+	s, b := rodtest.Setup(t)
+
+	h := testLoginHandler(s)
+	h.LoginURL = s.URLOf("iframe.html")
+
+	pt, err := NewBrowserTemplate(b).Login(h)
+	if err != nil {
+		t.Fatalf("Expecting login success, got %v", err)
+	}
+
+	if !pt.Has(".welcome") {
+		t.Errorf("Expecting welcome after login, got %s", pt.URL())
+	}
+}
+
+func TestLoginSubmitInPopup(t *testing.T) {
+	s, b := rodtest.Setup(t)
+
+	h := testLoginHandler(s)
+	h.LoginGateURL = s.URLOf("popup.html")
+
+	pt, err := NewBrowserTemplate(b).Login(h)
+	if err != nil {
+		t.Fatalf("Expecting login success, got %v", err)
+	}
+
+	if !pt.Has(".welcome") {
+		t.Errorf("Expecting welcome in the opener after login, got %s", pt.URL())
+	}
+}
+
+func TestLoginSubmitWrongPassword(t *testing.T) {
+	s, b := rodtest.Setup(t)
+
+	h := testLoginHandler(s)
+	h.Password = "wrong"
+
+	if _, err := NewBrowserTemplate(b).Login(h); !errors.Is(err, credential.ErrRejected) {
+		t.Errorf("Expecting credential rejected, got %v", err)
+	}
+}
+
+func TestLoginValidate(t *testing.T) {
+	t.Setenv("RODTEST_ID", "env-user")
+
+	l := &Login{Handler: LoginHandler{EnvID: "RODTEST_ID", EnvPassword: "RODTEST_PASSWORD"}}
+	if err := l.Validate(); err == nil {
+		t.Error("Expecting error without password")
+	}
+
+	t.Setenv("RODTEST_PASSWORD", "env-password")
+	if err := l.Validate(); err != nil {
+		t.Fatalf("Expecting no error, got %v", err)
+	}
+
+	if l.Handler.ID != "env-user" || l.Handler.Password != "env-password" {
+		t.Errorf("Expecting id and password from env, got %s, %s", l.Handler.ID, l.Handler.Password)
+	}
+}
+
+func TestLoginValidateSources(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("file-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Login{Handler: LoginHandler{
+		ID:                "user",
+		CredentialSources: []credential.Source{credential.SecretFile("", secretFile)},
+	}}
+
+	if err := l.Validate(); err != nil {
+		t.Fatalf("Expecting no error, got %v", err)
+	}
+
+	if l.Handler.Password != "file-password" || l.Credential.IDSource != "explicit" || l.Credential.SecretSource != "file:"+secretFile {
+		t.Errorf("Expecting id given and password from file, got %+v", l.Credential)
+	}
+}
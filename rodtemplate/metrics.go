@@ -0,0 +1,95 @@
+package rodtemplate
+
+import (
+	"net/url"
+	"time"
+)
+
+// kinds of WaitObservation
+const (
+	WaitKindWaitFor            = "WaitFor"
+	WaitKindWaitUntilHas       = "WaitUntilHas"
+	WaitKindClickWhenAvailable = "ClickWhenAvailable"
+	WaitKindFocusWhenAvailable = "FocusWhenAvailable"
+	WaitKindInput              = "Input"
+	WaitKindLoginInput         = "LoginInput"
+	WaitKindLoginSuccess       = "LoginSuccess"
+	WaitKindRetry              = "Retry"
+	WaitKindWaitUntil          = "WaitUntil"
+	WaitKindWorkflowStep       = "WorkflowStep"
+)
+
+// WaitObservation is a finished polling loop
+type WaitObservation struct {
+	// Kind is the polling function like WaitKindWaitFor
+	Kind string
+	// Site is the host of the page, empty if unknown
+	Site string
+	// Target is the selector or the target name, too various to be a metric label
+	Target   string
+	Attempts int
+	Elapsed  time.Duration
+	TimedOut bool
+}
+
+// Retries is the number of attempts after the first one
+func (o WaitObservation) Retries() int {
+	if o.Attempts < 1 {
+		return 0
+	}
+
+	return o.Attempts - 1
+}
+
+// LoginOutcome is how BrowserTemplate.Login ended
+type LoginOutcome string
+
+const (
+	LoginSucceeded LoginOutcome = "succeeded"
+	// LoginAlready is a login skipped for the session is already logged in
+	LoginAlready LoginOutcome = "already"
+	// LoginRejected is a login failed with credential.ErrRejected
+	LoginRejected LoginOutcome = "rejected"
+	LoginErrored  LoginOutcome = "errored"
+)
+
+// Metrics receives measurements of templates, like the prometheus adapter in rodtemplate/prommetrics
+type Metrics interface {
+	ObserveWait(o WaitObservation)
+	ObserveLogin(site string, outcome LoginOutcome, elapsed time.Duration)
+	ObserveScreenShot(site string, format string, size int)
+}
+
+// NopMetrics discards measurements, it is the default metrics
+var NopMetrics Metrics = nopMetrics{}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveWait(WaitObservation)                      {}
+func (nopMetrics) ObserveLogin(string, LoginOutcome, time.Duration) {}
+func (nopMetrics) ObserveScreenShot(string, string, int)            {}
+
+var defaultMetrics = NopMetrics
+
+// SetDefaultMetrics replaces the metrics of templates not having one and returns a func restoring the previous one
+func SetDefaultMetrics(m Metrics) (restore func()) {
+	prev := defaultMetrics
+	defaultMetrics = m
+	if defaultMetrics == nil {
+		defaultMetrics = NopMetrics
+	}
+
+	return func() {
+		defaultMetrics = prev
+	}
+}
+
+// siteOf returns the host of rawURL, empty if it is not a url
+func siteOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
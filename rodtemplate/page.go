@@ -1,45 +1,121 @@
 package rodtemplate
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"os"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/cdp"
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/proto"
-)
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-type ScreenShotOption struct {
-	Format      proto.PageCaptureScreenshotFormat
-	Quality     int
-	XDelta      float64
-	YDelta      float64
-	WidthDelta  float64
-	HeightDelta float64
-}
+	"github.com/darimuri/go-lib/credential"
+)
 
 var _ ElementSelector = (*PageTemplate)(nil)
 
 type PageTemplate struct {
 	P *rod.Page
+	// Logger receives records of the page, the one set by SetDefaultLogger if nil
+	Logger Logger
+	// TracerProvider starts spans of the page, the one set by SetDefaultTracerProvider if nil
+	TracerProvider trace.TracerProvider
+	// Metrics receives measurements of the page, the one set by SetDefaultMetrics if nil
+	Metrics Metrics
+}
+
+func (p *PageTemplate) logger() Logger {
+	if p != nil && p.Logger != nil {
+		return redacting(p.Logger)
+	}
+
+	return redacting(defaultLogger)
+}
+
+// derive returns a template of another page or frame sharing the logger, tracer provider and metrics of p
+func (p *PageTemplate) derive(page *rod.Page) *PageTemplate {
+	return &PageTemplate{P: page, Logger: p.Logger, TracerProvider: p.TracerProvider, Metrics: p.Metrics}
+}
+
+func (p *PageTemplate) metrics() Metrics {
+	if p != nil && p.Metrics != nil {
+		return p.Metrics
+	}
+
+	return defaultMetrics
+}
+
+// site returns the host of the page, empty if unknown
+func (p *PageTemplate) site() string {
+	if p == nil || p.P == nil {
+		return ""
+	}
+
+	info, err := p.P.Info()
+	if err != nil {
+		return ""
+	}
+
+	return siteOf(info.URL)
+}
+
+// observeWait reports a polling loop started at started
+func (p *PageTemplate) observeWait(kind, target string, attempts int, started time.Time, timedOut bool) {
+	m := p.metrics()
+	if m == NopMetrics {
+		return
+	}
+
+	m.ObserveWait(WaitObservation{
+		Kind:     kind,
+		Site:     p.site(),
+		Target:   target,
+		Attempts: attempts,
+		Elapsed:  time.Since(started),
+		TimedOut: timedOut,
+	})
+}
+
+// startSpan starts a span having the span in the page context as parent
+func (p *PageTemplate) startSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	var tp trace.TracerProvider
+	ctx := context.Background()
+
+	if p != nil {
+		tp = p.TracerProvider
+		if p.P != nil {
+			ctx = p.P.GetContext()
+			attrs = append(attrs, AttrFrameID.String(string(p.P.FrameID)))
+		}
+	}
+
+	_, span := startSpan(tp, ctx, name, attrs...)
+
+	return span
 }
 
 func (p *PageTemplate) El(selector string) *ElementTemplate {
-	return &ElementTemplate{Element: p.P.MustElement(selector)}
+	return p.Wrap(p.P.MustElement(selector))
 }
 
 func (p *PageTemplate) Els(selector string) ElementsTemplate {
-	return toElementsTemplate(p.P.MustElements(selector))
+	return toElementsTemplate(p.P.MustElements(selector), p)
+}
+
+// Wrap returns a template of el found through rod directly, sharing the logger, tracer provider and metrics of p
+func (p *PageTemplate) Wrap(el *rod.Element) *ElementTemplate {
+	return &ElementTemplate{Element: el, owner: p}
 }
 
 func (p *PageTemplate) Has(selector string) bool {
 	has, _, err := p.P.Has(selector)
 	if err != nil {
-		log.Printf("failed to find element using selector %s\n", selector)
+		p.logger().Warn("failed to find element", "selector", selector, "frame_id", p.P.FrameID, "error", err)
 		return false
 	}
 
@@ -59,22 +135,28 @@ func (p PageTemplate) WaitUntilHasForHour(selector string) bool {
 }
 
 func (p PageTemplate) WaitUntilHas(selector string, sleepDuration time.Duration, deadline time.Duration) bool {
-	deadlineTime := time.Now().Add(deadline)
-	for time.Now().Before(deadlineTime) {
-		if p.Has(selector) {
-			return true
-		}
-		time.Sleep(sleepDuration)
-	}
+	policy := timeoutPolicy(sleepDuration, deadline)
+
+	_, err := p.retry(p.context(), WaitKindWaitUntilHas, selector, policy, p.hasCondition(selector), nil)
+
+	return err == nil
+}
 
-	return false
+// hasCondition is satisfied when the page has selector
+func (p *PageTemplate) hasCondition(selector string) Condition {
+	return Check(func() bool {
+		return p.Has(selector)
+	})
 }
 
-func (p *PageTemplate) Navigate(url string) error {
+func (p *PageTemplate) Navigate(url string) (err error) {
 	if p.P == nil {
 		return errors.New("page is nil")
 	}
 
+	span := p.startSpan("rodtemplate.Navigate", AttrURL.String(url))
+	defer endSpan(span, &err)
+
 	p.P.MustNavigate(url)
 
 	p.P.MustWaitRequestIdle()
@@ -83,6 +165,9 @@ func (p *PageTemplate) Navigate(url string) error {
 }
 
 func (p *PageTemplate) ClickElement(selector string) {
+	span := p.startSpan("rodtemplate.ClickElement", AttrSelector.String(selector))
+	defer endSpan(span, nil)
+
 	p.P.MustWaitIdle()
 
 	el := p.P.MustElement(selector)
@@ -91,32 +176,37 @@ func (p *PageTemplate) ClickElement(selector string) {
 	el.MustClick()
 }
 
+// ClickWhenAvailable clicks the element of selector once it is visible following AvailablePolicy
 func (p *PageTemplate) ClickWhenAvailable(selector string) bool {
-	for i := 0; i < 1000; i++ {
-		if p.Has(selector) {
-			el := p.El(selector)
-			if el.MustVisible() {
-				el.MustFocus()
-				el.MustScrollIntoView()
-				el.MustClick()
-				return true
-			}
-		}
-		time.Sleep(time.Millisecond * 100)
+	span := p.startSpan("rodtemplate.ClickWhenAvailable", AttrSelector.String(selector))
+	defer endSpan(span, nil)
+
+	var el *ElementTemplate
+	visible := func() (bool, error) {
+		el = p.El(selector)
+		return el.MustVisible(), nil
 	}
-	return false
+
+	if _, err := p.retry(p.context(), WaitKindClickWhenAvailable, selector, AvailablePolicy, AllOf(p.hasCondition(selector), visible), span); err != nil {
+		return false
+	}
+
+	el.MustFocus()
+	el.MustScrollIntoView()
+	el.MustClick()
+
+	return true
 }
 
+// FocusWhenAvailable focuses the element of selector once the page has it following AvailablePolicy
 func (p *PageTemplate) FocusWhenAvailable(selector string) bool {
-	for i := 0; i < 1000; i++ {
-		if p.Has(selector) {
-			el := p.El(selector)
-			el.MustFocus()
-			return true
-		}
-		time.Sleep(time.Millisecond * 100)
+	if _, err := p.retry(p.context(), WaitKindFocusWhenAvailable, selector, AvailablePolicy, p.hasCondition(selector), nil); err != nil {
+		return false
 	}
-	return false
+
+	p.El(selector).MustFocus()
+
+	return true
 }
 
 func (p *PageTemplate) MoveMouseTo(el *rod.Element) {
@@ -126,7 +216,7 @@ func (p *PageTemplate) MoveMouseTo(el *rod.Element) {
 		p.P.Mouse.MustMoveTo(point.X, point.Y)
 	} else {
 		if cErr, ok := err.(*cdp.Error); ok {
-			log.Println("failed to get element shape", cErr)
+			p.logger().Warn("failed to get element shape", "frame_id", p.P.FrameID, "error", cErr)
 		} else {
 			panic(err)
 		}
@@ -137,16 +227,18 @@ func (p *PageTemplate) URL() string {
 	return p.P.MustInfo().URL
 }
 
+// Input types value into the element of selector, value is never put into spans or logs
 func (p *PageTemplate) Input(selector string, value string) {
-	for i := 0; i < 100; i++ {
-		if p.P.MustHas(selector) {
-			break
-		}
-		time.Sleep(time.Millisecond * 100)
-	}
+	span := p.startSpan("rodtemplate.Input", AttrSelector.String(selector))
+	defer endSpan(span, nil)
 
-	if !p.P.MustHas(selector) {
-		log.Fatalf("failed to find input having selector %s\n", selector)
+	mustHas := Check(func() bool {
+		return p.P.MustHas(selector)
+	})
+
+	if _, err := p.retry(p.context(), WaitKindInput, selector, InputPolicy, mustHas, span); err != nil {
+		p.logger().Error("failed to find input", "selector", selector, "frame_id", p.P.FrameID)
+		panic(fmt.Errorf("failed to find input having selector %s", selector))
 	}
 
 	el := p.P.MustElement(selector)
@@ -197,7 +289,7 @@ func (p *PageTemplate) FrameID() proto.PageFrameID {
 
 func (p *PageTemplate) WaitRepaint() {
 	if err := p.P.WaitRepaint(); err != nil {
-		log.Println("failed to wait", err)
+		p.logger().Warn("failed to wait repaint", "frame_id", p.P.FrameID, "error", err)
 	}
 }
 
@@ -227,7 +319,7 @@ func (p *PageTemplate) ScrollTo(e *ElementTemplate) {
 	quad := e.MustShape().Quads[0]
 	bottom := quad[7]
 	if err := p.P.Mouse.Scroll(0.0, bottom, 1); err != nil {
-		log.Println("failed to scroll mouse", err)
+		p.logger().Warn("failed to scroll mouse", "frame_id", p.P.FrameID, "error", err)
 	}
 }
 
@@ -239,6 +331,16 @@ func (p *PageTemplate) HTML() string {
 	return p.El("html").MustHTML()
 }
 
+// DumpHTML writes html of the page to dumpPath masking secrets known to credential.Secrets
+func (p *PageTemplate) DumpHTML(dumpPath string) error {
+	var html string
+	if err := catch(func() { html = p.HTML() }); err != nil {
+		return credential.RedactError(err)
+	}
+
+	return os.WriteFile(dumpPath, []byte(credential.Redact(html)), 0644)
+}
+
 func (p *PageTemplate) Event() <-chan *rod.Message {
 	return p.P.Event()
 }
@@ -261,33 +363,18 @@ func (p *PageTemplate) ScreenShotFull(dumpPath string) []byte {
 	return p.ScreenShotFullWithOption(dumpPath, opt)
 }
 
+// ScreenShotFullWithOption captures the whole page into dumpPath, it panics on failure unlike ScreenShotFullBytes
 func (p *PageTemplate) ScreenShotFullWithOption(dumpPath string, opt ScreenShotOption) []byte {
-	metrics, err := proto.PageGetLayoutMetrics{}.Call(p.P)
+	byteArr, err := p.ScreenShotFullBytes(opt)
 	if err != nil {
 		panic(err)
 	}
 
-	oldView := proto.EmulationSetDeviceMetricsOverride{}
-	set := p.P.LoadState(&oldView)
-	view := oldView
-	view.Width = int(metrics.ContentSize.Width)
-	view.Height = int(metrics.ContentSize.Height)
-
-	err = p.P.SetViewport(&view)
-	if err != nil {
+	if err = os.WriteFile(dumpPath, byteArr, 0644); err != nil {
 		panic(err)
 	}
 
-	defer func() { // try to recover the viewport
-		if !set {
-			_ = proto.EmulationClearDeviceMetricsOverride{}.Call(p.P)
-			return
-		}
-
-		_ = p.P.SetViewport(&oldView)
-	}()
-
-	return p.ScreenShotWithOption(p.El("html"), dumpPath, opt)
+	return byteArr
 }
 
 func (p *PageTemplate) ScreenShot(el *ElementTemplate, dumpPath string, yDelta float64) []byte {
@@ -300,37 +387,15 @@ func (p *PageTemplate) ScreenShot(el *ElementTemplate, dumpPath string, yDelta f
 	return p.ScreenShotWithOption(el, dumpPath, opt)
 }
 
+// ScreenShotWithOption captures el into dumpPath, it panics on failure unlike ScreenShotBytes
 func (p *PageTemplate) ScreenShotWithOption(el *ElementTemplate, dumpPath string, opt ScreenShotOption) []byte {
-	err := el.ScrollIntoView()
+	byteArr, err := p.ScreenShotBytes(el, opt)
 	if err != nil {
 		panic(err)
 	}
 
-	quad := el.MustShape().Quads[0]
-
-	width := quad[2] - quad[0] + opt.WidthDelta
-	height := quad[7] - quad[1] + opt.HeightDelta
-
-	req := &proto.PageCaptureScreenshot{
-		Format:  opt.Format,
-		Quality: &opt.Quality,
-		Clip: &proto.PageViewport{
-			X:      quad[0] + opt.XDelta,
-			Y:      quad[1] + opt.YDelta,
-			Width:  width,
-			Height: height,
-			Scale:  1,
-		},
-	}
-
-	byteArr, errScreenShot := p.P.Screenshot(false, req)
-	if errScreenShot != nil {
-		panic(errScreenShot)
-	}
-
-	errWrite := ioutil.WriteFile(dumpPath, byteArr, 0644)
-	if errWrite != nil {
-		panic(errWrite)
+	if err = os.WriteFile(dumpPath, byteArr, 0644); err != nil {
+		panic(err)
 	}
 
 	return byteArr
@@ -0,0 +1,231 @@
+package rodtemplate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+
+	"github.com/darimuri/go-lib/credential"
+	"github.com/darimuri/go-lib/rodtemplate/rodtest"
+)
+
+func openFixture(t *testing.T, path string) (*rodtest.Server, *BrowserTemplate, *PageTemplate) {
+	t.Helper()
+
+	s, b := rodtest.Setup(t)
+	p := rodtest.Page(t, b, s.URLOf(path))
+
+	return s, NewBrowserTemplate(b), NewPageTemplate(p)
+}
+
+func TestScreenShotFullWithOption(t *testing.T) {
+	_, _, pt := openFixture(t, "scroll.html")
+
+	dumpPath := filepath.Join(t.TempDir(), "full.png")
+	b := pt.ScreenShotFullWithOption(dumpPath, ScreenShotOption{Format: proto.PageCaptureScreenshotFormatPng})
+
+	if len(b) == 0 {
+		t.Fatal("Expecting screenshot bytes, got empty")
+	}
+
+	stat, err := os.Stat(dumpPath)
+	if err != nil {
+		t.Fatalf("Expecting screenshot file, got %v", err)
+	}
+
+	if stat.Size() != int64(len(b)) {
+		t.Errorf("Expecting %d bytes written, got %d", len(b), stat.Size())
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	_, _, pt := openFixture(t, "pages.html")
+
+	for _, opt := range []PaginateOption{
+		{Mode: PaginateNextButton, ItemSelector: ".item", NextSelector: ".next"},
+		{Mode: PaginateNumbered, ItemSelector: ".item", PageLinkSelector: ".page"},
+		{Mode: PaginateURLParam, ItemSelector: ".item", URLParam: "page"},
+	} {
+		if err := pt.Navigate(strings.Split(pt.URL(), "?")[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		pages := 0
+		items := 0
+		err := pt.Paginate(context.Background(), opt, func(page int, els ElementsTemplate) error {
+			pages = page
+			items += len(els)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Expecting no error for mode %d, got %v", opt.Mode, err)
+		}
+
+		if pages != 3 || items != 15 {
+			t.Errorf("Expecting 3 pages of 15 items for mode %d, got %d pages of %d items", opt.Mode, pages, items)
+		}
+	}
+}
+
+func TestPaginateURLParamClamped(t *testing.T) {
+	s, _, pt := openFixture(t, "pages.html")
+
+	if err := pt.Navigate(s.URLOf("pages.html?clamp=1")); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := 0
+	err := pt.Paginate(context.Background(), PaginateOption{Mode: PaginateURLParam, ItemSelector: ".item", URLParam: "page"},
+		func(page int, els ElementsTemplate) error {
+			pages = page
+			return nil
+		})
+
+	if err != nil || pages != 3 {
+		t.Errorf("Expecting 3 pages stopped at the clamped page, got %d pages, %v", pages, err)
+	}
+}
+
+func TestPaginateInfiniteScroll(t *testing.T) {
+	_, _, pt := openFixture(t, "scroll.html")
+
+	items := 0
+	err := pt.Paginate(context.Background(), PaginateOption{Mode: PaginateInfiniteScroll, ItemSelector: ".item", MaxPages: 3},
+		func(page int, els ElementsTemplate) error {
+			items += len(els)
+			return nil
+		})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if items != 60 {
+		t.Errorf("Expecting 60 items in 3 pages, got %d", items)
+	}
+}
+
+func TestRecordHAR(t *testing.T) {
+	s, _, pt := openFixture(t, "gate.html")
+
+	r := pt.RecordHAR(HAROption{WithBody: true})
+	if err := pt.Navigate(s.URLOf("login.html")); err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+
+	har := r.HAR()
+	if har.Log.Version != "1.2" {
+		t.Errorf("Expecting HAR 1.2, got %s", har.Log.Version)
+	}
+
+	for _, e := range har.Log.Entries {
+		if e.Request.URL == s.URLOf("login.html") {
+			if e.Response.Status != 200 || !strings.Contains(e.Response.Content.Text, "password") {
+				t.Errorf("Expecting login page body, got %d %q", e.Response.Status, e.Response.Content.Text)
+			}
+			return
+		}
+	}
+
+	t.Errorf("Expecting login.html in entries, got %d entries", len(har.Log.Entries))
+}
+
+func TestHAREntryClone(t *testing.T) {
+	e := &HAREntry{Request: HARRequest{
+		Headers:  []HARNameValue{{Name: "Accept", Value: "*/*"}},
+		PostData: &HARPostData{Text: "a=1"},
+	}}
+
+	c := e.clone()
+	e.Request.Headers[0].Value = "text/html"
+	e.Request.PostData.Text = "a=2"
+
+	if c.Request.Headers[0].Value != "*/*" || c.Request.PostData.Text != "a=1" {
+		t.Errorf("Expecting clone unchanged, got %+v", c.Request)
+	}
+}
+
+func TestRedactHAR(t *testing.T) {
+	credential.Secrets.Add("har secret")
+	defer credential.Secrets.Remove("har secret")
+
+	h := &HAR{Log: HARLog{Entries: []*HAREntry{{
+		Request: HARRequest{
+			URL:      "http://localhost/login?password=har+secret",
+			PostData: &HARPostData{Text: "id=user&password=har+secret"},
+		},
+		Response: HARResponse{Content: HARContent{Text: `{"echo":"har secret"}`}},
+	}}}}
+
+	redacted := redactHAR(h)
+	e := redacted.Log.Entries[0]
+
+	if e.Request.URL != "http://localhost/login?password=[REDACTED]" ||
+		e.Request.PostData.Text != "id=user&password=[REDACTED]" ||
+		e.Response.Content.Text != `{"echo":"[REDACTED]"}` {
+		t.Errorf("Expecting secret redacted, got %+v", e)
+	}
+
+	if h.Log.Entries[0].Request.PostData.Text != "id=user&password=har+secret" {
+		t.Error("Expecting original HAR unchanged")
+	}
+}
+
+func TestHARMasksSecretAsRecorded(t *testing.T) {
+	credential.Secrets.Add("recorded secret")
+
+	r := &HARRecorder{pending: map[proto.NetworkRequestID]*harPending{}}
+	r.onRequestWillBeSent(&proto.NetworkRequestWillBeSent{
+		RequestID: "1",
+		Request: &proto.NetworkRequest{
+			URL:         "http://localhost/login",
+			Method:      "POST",
+			HasPostData: true,
+			PostData:    "password=recorded+secret",
+		},
+	})
+
+	credential.Secrets.Remove("recorded secret")
+
+	if text := r.HAR().Log.Entries[0].Request.PostData.Text; text != "password=[REDACTED]" {
+		t.Errorf("Expecting secret masked after it is removed, got %s", text)
+	}
+}
+
+func TestRedactHARSecretEscapedInJSON(t *testing.T) {
+	secret := `p"a\ss`
+	credential.Secrets.Add(secret)
+	defer credential.Secrets.Remove(secret)
+
+	h := &HAR{Log: HARLog{Entries: []*HAREntry{{
+		Request: HARRequest{
+			Headers:     []HARNameValue{{Name: "X-Password", Value: secret}},
+			QueryString: []HARNameValue{{Name: "password", Value: secret}},
+			PostData:    &HARPostData{Text: `{"password":"p\"a\\ss"}`},
+		},
+		Response: HARResponse{Cookies: []HARCookie{{Name: "pw", Value: secret}}},
+	}}}}
+
+	e := redactHAR(h).Log.Entries[0]
+
+	if e.Request.Headers[0].Value != credential.Redacted || e.Request.QueryString[0].Value != credential.Redacted ||
+		e.Response.Cookies[0].Value != credential.Redacted {
+		t.Errorf("Expecting secret redacted in headers, query and cookies, got %+v", e)
+	}
+
+	if e.Request.PostData.Text != `{"password":"[REDACTED]"}` {
+		t.Errorf("Expecting json escaped secret redacted, got %s", e.Request.PostData.Text)
+	}
+
+	b, err := json.Marshal(redactHAR(h))
+	if err != nil || strings.Contains(string(b), `a\\ss`) {
+		t.Errorf("Expecting valid json without secret, got %s %v", b, err)
+	}
+}
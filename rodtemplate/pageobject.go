@@ -0,0 +1,334 @@
+package rodtemplate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// PageObject is a page described by a struct of `rod` tagged element fields, opened by PageTemplate.Open.
+//
+// Tag options are the css and optional options of Extract:
+//
+//	LoginButton *rodtemplate.ElementTemplate `rod:"css=#submit"`
+//	Errors      rodtemplate.ElementsTemplate `rod:"css=.error,optional"`
+//	Header      HeaderComponent              `rod:"css=header"`
+//
+//	Results     *rodtemplate.Locator         `rod:"css=#results"`
+//
+// Fields of *Locator are bound lazily, they find their element on every access so that an element replaced by
+// the page, like a list rendered again, is never stale. Fields of *ElementTemplate and ElementsTemplate are
+// snapshots of the first element and every element of css when the page object is bound.
+// Struct fields, or pointers to them, are components whose fields are found under the element of css,
+// looked up again on every access by their Locator fields. An untagged *PageTemplate field is set to the page so
+// that methods of the page object can act on it.
+type PageObject interface {
+	// URL is navigated by Open, the current page is bound if empty
+	URL() string
+	// Ready is the condition of the page to be bound like c.URLMatches(re), nil to wait only for required elements
+	Ready(c Conditions) Condition
+}
+
+// BindError lists every required element which is not found or field which can not be bound
+type BindError struct {
+	Fields []*FieldError
+}
+
+func (e *BindError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Error())
+	}
+
+	return fmt.Sprintf("failed to bind %d field(s)\n%s", len(e.Fields), strings.Join(messages, "\n"))
+}
+
+var (
+	elementTemplateType  = reflect.TypeOf((*ElementTemplate)(nil))
+	elementsTemplateType = reflect.TypeOf(ElementsTemplate(nil))
+	pageTemplateType     = reflect.TypeOf((*PageTemplate)(nil))
+	locatorType          = reflect.TypeOf((*Locator)(nil))
+)
+
+// Locator is a page object field finding its element by its selector on every access
+type Locator struct {
+	scope ElementSelector
+	css   string
+}
+
+// Selector returns the css of the locator
+func (l *Locator) Selector() string {
+	return l.css
+}
+
+// Exists tells the element of the locator exists now
+func (l *Locator) Exists() bool {
+	return l.mustScope().Has(l.css)
+}
+
+// Element finds the first element of the locator, it panics if not found like El
+func (l *Locator) Element() *ElementTemplate {
+	return l.mustScope().El(l.css)
+}
+
+// Elements finds every element of the locator
+func (l *Locator) Elements() ElementsTemplate {
+	return l.mustScope().Els(l.css)
+}
+
+func (l *Locator) mustScope() ElementSelector {
+	if l == nil || l.scope == nil {
+		panic(errors.New("locator is not bound"))
+	}
+
+	return l.scope
+}
+
+// locatorScope finds descendants of the element of a locator, found again for every lookup
+type locatorScope struct {
+	l *Locator
+}
+
+func (s locatorScope) El(selector string) *ElementTemplate {
+	return s.l.Element().El(selector)
+}
+
+func (s locatorScope) Els(selector string) ElementsTemplate {
+	return s.l.Element().Els(selector)
+}
+
+func (s locatorScope) Has(selector string) bool {
+	return s.l.Exists() && s.l.Element().Has(selector)
+}
+
+// Open navigates to the url of po, waits up to timeout until it is ready and its required elements exist, then binds po.
+// po must be a pointer to struct. *BindError lists the required elements missing at the timeout,
+// *TimeoutError is returned if they exist but the page is not ready.
+func (p *PageTemplate) Open(po PageObject, timeout time.Duration) error {
+	if url := po.URL(); url != "" {
+		if err := p.Navigate(url); err != nil {
+			return err
+		}
+	}
+
+	return p.Await(po, timeout)
+}
+
+// Await waits up to timeout until po is ready on the current page and binds it like Open
+func (p *PageTemplate) Await(po PageObject, timeout time.Duration) error {
+	selectors, err := requiredSelectors(po)
+	if err != nil {
+		return err
+	}
+
+	c := p.Cond()
+
+	conditions := make([]Condition, 0, len(selectors)+1)
+	if ready := po.Ready(c); ready != nil {
+		conditions = append(conditions, ready)
+	}
+	for _, selector := range selectors {
+		conditions = append(conditions, c.Exists(selector))
+	}
+
+	errWait := p.WaitUntil(fmt.Sprintf("%T", po), timeout, AllOf(conditions...))
+
+	// bind even on timeout to report every missing element at once
+	if err = p.Bind(po); err != nil {
+		return err
+	}
+
+	return errWait
+}
+
+// Bind binds `rod` tagged element fields of dst, a pointer to struct, to elements of the page without waiting
+func (p *PageTemplate) Bind(dst interface{}) error {
+	return bind(p, p, dst)
+}
+
+// Bind binds `rod` tagged element fields of dst, a pointer to struct, to descendants of the element
+func (e ElementTemplate) Bind(dst interface{}) error {
+	return bind(&e, nil, dst)
+}
+
+func bind(es ElementSelector, p *PageTemplate, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("bind destination must be a non nil pointer to struct")
+	}
+
+	b := &binder{page: p}
+	b.bindStruct(es, v.Elem(), "")
+
+	if len(b.errors) > 0 {
+		return &BindError{Fields: b.errors}
+	}
+
+	return nil
+}
+
+// requiredSelectors returns css of required fields of po, not of its components
+func requiredSelectors(po PageObject) ([]string, error) {
+	t := reflect.TypeOf(po)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("page object must be a pointer to struct, got %s", t)
+	}
+	t = t.Elem()
+
+	selectors := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(ExtractTag)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		opt, err := parseExtractTag(tag)
+		if err != nil {
+			return nil, &BindError{Fields: []*FieldError{{Field: sf.Name, Err: err}}}
+		}
+
+		if opt.css != "" && !opt.optional {
+			selectors = append(selectors, opt.css)
+		}
+	}
+
+	return selectors, nil
+}
+
+type binder struct {
+	page   *PageTemplate
+	errors []*FieldError
+}
+
+func (b *binder) fail(field, selector string, err error) {
+	b.errors = append(b.errors, &FieldError{Field: field, Selector: selector, Err: err})
+}
+
+func (b *binder) bindStruct(es ElementSelector, v reflect.Value, prefix string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup(ExtractTag)
+		if !ok {
+			if sf.Type == pageTemplateType && b.page != nil {
+				v.Field(i).Set(reflect.ValueOf(b.page))
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		name := prefix + sf.Name
+
+		opt, err := parseExtractTag(tag)
+		if err != nil {
+			b.fail(name, "", err)
+			continue
+		}
+
+		if opt.css == "" {
+			b.fail(name, "", errors.New("css is required to bind a field"))
+			continue
+		}
+
+		b.bindField(es, v.Field(i), name, opt)
+	}
+}
+
+func (b *binder) bindField(es ElementSelector, fv reflect.Value, name string, opt extractOption) {
+	if fv.Type() == elementsTemplateType {
+		var els ElementsTemplate
+		if err := catch(func() {
+			if es.Has(opt.css) {
+				els = es.Els(opt.css)
+			}
+		}); err != nil {
+			b.fail(name, opt.css, err)
+			return
+		}
+
+		if len(els) == 0 && !opt.optional {
+			b.fail(name, opt.css, errors.New("element is not found"))
+			return
+		}
+
+		fv.Set(reflect.ValueOf(els))
+		return
+	}
+
+	if fv.Type() == locatorType {
+		// existence is checked once to report a missing required element, the element is found on access
+		if !opt.optional {
+			found, err := has(es, opt.css)
+			if err == nil && !found {
+				err = errors.New("element is not found")
+			}
+			if err != nil {
+				b.fail(name, opt.css, err)
+				return
+			}
+		}
+
+		fv.Set(reflect.ValueOf(&Locator{scope: es, css: opt.css}))
+		return
+	}
+
+	isComponent := fv.Kind() == reflect.Struct || fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct && fv.Type() != elementTemplateType
+	if fv.Type() != elementTemplateType && !isComponent {
+		b.fail(name, opt.css, fmt.Errorf("can not bind element to %s", fv.Type()))
+		return
+	}
+
+	found, err := has(es, opt.css)
+	if err != nil {
+		b.fail(name, opt.css, err)
+		return
+	}
+
+	if !found {
+		if !opt.optional {
+			b.fail(name, opt.css, errors.New("element is not found"))
+		}
+		fv.Set(reflect.Zero(fv.Type()))
+		return
+	}
+
+	if fv.Type() == elementTemplateType {
+		var el *ElementTemplate
+		if err = catch(func() { el = es.El(opt.css) }); err != nil {
+			b.fail(name, opt.css, err)
+			return
+		}
+
+		fv.Set(reflect.ValueOf(el))
+		return
+	}
+
+	// a component finds the element of css again for every lookup of its fields
+	scope := locatorScope{l: &Locator{scope: es, css: opt.css}}
+
+	if fv.Kind() == reflect.Ptr {
+		component := reflect.New(fv.Type().Elem())
+		b.bindStruct(scope, component.Elem(), name+".")
+		fv.Set(component)
+		return
+	}
+
+	b.bindStruct(scope, fv, name+".")
+}
+
+func has(es ElementSelector, css string) (found bool, err error) {
+	err = catch(func() { found = es.Has(css) })
+
+	return found, err
+}
@@ -0,0 +1,179 @@
+package rodtemplate
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type loginForm struct {
+	ID       *ElementTemplate `rod:"css=#id"`
+	Password *ElementTemplate `rod:"css=#password"`
+}
+
+type loginPage struct {
+	*PageTemplate
+	url string
+
+	Form    loginForm        `rod:"css=#login"`
+	Inputs  ElementsTemplate `rod:"css=input"`
+	Submit  *ElementTemplate `rod:"css=#submit"`
+	Errors  ElementsTemplate `rod:"css=.error,optional"`
+	Captcha *loginForm       `rod:"css=.captcha,optional"`
+}
+
+func (l *loginPage) URL() string {
+	return l.url
+}
+
+func (l *loginPage) Ready(c Conditions) Condition {
+	return c.URLMatches(regexp.MustCompile(`/login\.html$`))
+}
+
+type missingPage struct {
+	Title   *ElementTemplate `rod:"css=h1"`
+	Missing *ElementTemplate `rod:"css=#missing"`
+	Form    struct {
+		Field *ElementTemplate `rod:"css=#field"`
+	} `rod:"css=#login"`
+}
+
+func (*missingPage) URL() string {
+	return ""
+}
+
+func (*missingPage) Ready(Conditions) Condition {
+	return nil
+}
+
+type emptySelector struct{}
+
+func (emptySelector) El(string) *ElementTemplate  { panic("not found") }
+func (emptySelector) Els(string) ElementsTemplate { panic("not found") }
+func (emptySelector) Has(string) bool             { return false }
+
+// freshSelector finds a new element on every lookup like a page rendering the element again
+type freshSelector struct {
+	lookups *int
+}
+
+func (f freshSelector) El(string) *ElementTemplate {
+	*f.lookups++
+	return &ElementTemplate{}
+}
+func (f freshSelector) Els(string) ElementsTemplate { return ElementsTemplate{f.El("")} }
+func (f freshSelector) Has(string) bool             { return true }
+
+func TestBindLocator(t *testing.T) {
+	var page struct {
+		Results *Locator `rod:"css=#results"`
+		Panel   *struct {
+			Rows *Locator `rod:"css=tr,optional"`
+		} `rod:"css=.panel"`
+		Ads *Locator `rod:"css=.ad,optional"`
+	}
+
+	lookups := 0
+	if err := bind(freshSelector{lookups: &lookups}, nil, &page); err != nil {
+		t.Fatalf("Expecting bound, got %v", err)
+	}
+
+	if lookups != 0 {
+		t.Errorf("Expecting no element found while binding locators, got %d lookups", lookups)
+	}
+
+	if page.Results.Selector() != "#results" || page.Results.Element() == page.Results.Element() {
+		t.Errorf("Expecting the element of #results found on every access, got %s", page.Results.Selector())
+	}
+
+	if scope, ok := page.Panel.Rows.scope.(locatorScope); !ok || scope.l.Selector() != ".panel" {
+		t.Errorf("Expecting rows found under the panel found on access, got %v", page.Panel.Rows.scope)
+	}
+
+	var unbound *Locator
+	if err := catch(func() { unbound.Element() }); err == nil {
+		t.Error("Expecting panic of unbound locator")
+	}
+
+	var be *BindError
+	if err := bind(emptySelector{}, nil, &page); !errors.As(err, &be) || len(be.Fields) != 2 || page.Ads == nil {
+		t.Errorf("Expecting missing results and panel with optional ads bound, got %v", err)
+	}
+}
+
+func TestRequiredSelectors(t *testing.T) {
+	selectors, err := requiredSelectors(&loginPage{})
+	if err != nil || len(selectors) != 3 || selectors[0] != "#login" || selectors[2] != "#submit" {
+		t.Errorf("Expecting required selectors of top level fields, got %v, %v", selectors, err)
+	}
+
+	var be *BindError
+	if _, err = requiredSelectors(&struct {
+		missingPage
+		Broken *ElementTemplate `rod:"selector=a"`
+	}{}); !errors.As(err, &be) {
+		t.Errorf("Expecting BindError for invalid tag, got %v", err)
+	}
+}
+
+func TestBindMissing(t *testing.T) {
+	var page struct {
+		*PageTemplate
+		Title    *ElementTemplate `rod:"css=h1"`
+		Items    ElementsTemplate `rod:"css=li"`
+		Optional *ElementTemplate `rod:"css=.optional,optional"`
+		Text     string           `rod:"css=p"`
+		NoCSS    *ElementTemplate `rod:"optional"`
+	}
+
+	var be *BindError
+	if err := bind(emptySelector{}, nil, &page); !errors.As(err, &be) || len(be.Fields) != 4 {
+		t.Fatalf("Expecting 4 failed fields, got %v", err)
+	}
+
+	for i, field := range []string{"Title", "Items", "Text", "NoCSS"} {
+		if be.Fields[i].Field != field {
+			t.Errorf("Expecting %s failed at %d, got %s", field, i, be.Fields[i].Field)
+		}
+	}
+
+	if err := bind(emptySelector{}, nil, page); err == nil {
+		t.Error("Expecting error for non pointer destination")
+	}
+}
+
+func TestPageObjectOpen(t *testing.T) {
+	s, _, pt := openFixture(t, "gate.html")
+
+	login := &loginPage{url: s.URLOf("login.html")}
+	if err := pt.Open(login, 5*time.Second); err != nil {
+		t.Fatalf("Expecting login page opened, got %v", err)
+	}
+
+	if login.PageTemplate != pt || login.Form.ID == nil || login.Form.Password == nil || login.Submit == nil {
+		t.Fatalf("Expecting fields bound, got %+v", login)
+	}
+
+	if len(login.Inputs) != 2 || len(login.Errors) != 0 || login.Captcha != nil {
+		t.Errorf("Expecting 2 inputs without errors and captcha, got %d, %d, %v", len(login.Inputs), len(login.Errors), login.Captcha)
+	}
+
+	var fresh struct {
+		Submit *Locator `rod:"css=#submit"`
+	}
+	if err := pt.Bind(&fresh); err != nil {
+		t.Fatalf("Expecting submit bound, got %v", err)
+	}
+
+	pt.P.MustEval(`() => document.getElementById('submit').outerHTML = '<button id="submit">Sign in</button>'`)
+
+	if text := fresh.Submit.Element().MustText(); text != "Sign in" {
+		t.Errorf("Expecting the replaced submit found by the locator, got %s", text)
+	}
+
+	var be *BindError
+	if err := pt.Await(&missingPage{}, 300*time.Millisecond); !errors.As(err, &be) || len(be.Fields) != 3 {
+		t.Errorf("Expecting 3 missing elements in one error, got %v", err)
+	}
+}
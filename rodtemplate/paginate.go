@@ -0,0 +1,334 @@
+package rodtemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PaginateMode int
+
+const (
+	// PaginateNextButton clicks NextSelector until it's gone or disabled
+	PaginateNextButton PaginateMode = iota
+	// PaginateNumbered clicks the link in PageLinkSelector having the next page number as text
+	PaginateNumbered
+	// PaginateURLParam navigates to the current url with URLParam set to the page number
+	PaginateURLParam
+	// PaginateInfiniteScroll scrolls to the bottom until item count stops growing
+	PaginateInfiniteScroll
+)
+
+// ErrStopPaginate can be returned from PaginateFunc to stop paginating without error
+var ErrStopPaginate = errors.New("stop paginate")
+
+// PaginateFunc is called with items of each page. On infinite scroll only newly loaded items are given.
+type PaginateFunc func(page int, items ElementsTemplate) error
+
+type PaginateOption struct {
+	Mode         PaginateMode
+	ItemSelector string
+
+	NextSelector     string
+	PageLinkSelector string
+
+	URLParam  string
+	StartPage int
+
+	// MaxPages stops after the number of pages, 0 means no limit
+	MaxPages int
+	// WaitTimeout is how long to wait for next page items, defaults to 10 seconds
+	WaitTimeout time.Duration
+	// HumanScroll scrolls with mouse wheel instead of End key on infinite scroll
+	HumanScroll bool
+}
+
+// Paginate calls f with items of every page until there is no more page, MaxPages is reached or ctx is done
+func (p *PageTemplate) Paginate(ctx context.Context, opt PaginateOption, f PaginateFunc) error {
+	if opt.ItemSelector == "" {
+		return errors.New("ItemSelector is required")
+	}
+
+	if opt.WaitTimeout == 0 {
+		opt.WaitTimeout = 10 * time.Second
+	}
+
+	pt := p.derive(p.P.Context(ctx))
+
+	var err error
+	switch opt.Mode {
+	case PaginateNextButton:
+		if opt.NextSelector == "" {
+			return errors.New("NextSelector is required")
+		}
+		err = pt.paginateByClick(ctx, opt, f, pt.nextButtonFinder(opt.NextSelector))
+	case PaginateNumbered:
+		if opt.PageLinkSelector == "" {
+			return errors.New("PageLinkSelector is required")
+		}
+		err = pt.paginateByClick(ctx, opt, f, pt.pageLinkFinder(opt.PageLinkSelector))
+	case PaginateURLParam:
+		if opt.URLParam == "" {
+			return errors.New("URLParam is required")
+		}
+		err = pt.paginateByURL(ctx, opt, f)
+	case PaginateInfiniteScroll:
+		err = pt.paginateByScroll(ctx, opt, f)
+	default:
+		return fmt.Errorf("unknown paginate mode %d", opt.Mode)
+	}
+
+	if errors.Is(err, ErrStopPaginate) {
+		return nil
+	}
+
+	return err
+}
+
+func (p *PageTemplate) items(selector string) (items ElementsTemplate, err error) {
+	err = catch(func() {
+		if p.Has(selector) {
+			items = p.Els(selector)
+		}
+	})
+
+	return items, err
+}
+
+// itemsSignature is used to tell whether items are replaced after a click
+func (p *PageTemplate) itemsSignature(selector string) string {
+	items, err := p.items(selector)
+	if err != nil || len(items) == 0 {
+		return ""
+	}
+
+	first, errFirst := items[0].HTML()
+	last, errLast := items[len(items)-1].HTML()
+	if errFirst != nil || errLast != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d|%s|%s", len(items), first, last)
+}
+
+func reachedMaxPages(opt PaginateOption, pages int) bool {
+	return opt.MaxPages > 0 && pages >= opt.MaxPages
+}
+
+// paginateByClick clicks the element returned by next(current page) to move to the next page
+func (p *PageTemplate) paginateByClick(ctx context.Context, opt PaginateOption, f PaginateFunc, next func(page int) *ElementTemplate) error {
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, err := p.items(opt.ItemSelector)
+		if err != nil {
+			return err
+		}
+
+		if err = f(page, items); err != nil {
+			return err
+		}
+
+		if reachedMaxPages(opt, page) {
+			return nil
+		}
+
+		el := next(page)
+		if el == nil {
+			return nil
+		}
+
+		before := p.itemsSignature(opt.ItemSelector)
+		beforeURL := p.URL()
+
+		if err = catch(func() {
+			el.MustScrollIntoView()
+			el.MustClick()
+		}); err != nil {
+			return err
+		}
+
+		if err = waitFor(p, fmt.Sprintf("page %d of %s", page+1, opt.ItemSelector), opt.WaitTimeout, 100*time.Millisecond,
+			func() bool {
+				return ctx.Err() != nil || p.URL() != beforeURL || p.itemsSignature(opt.ItemSelector) != before
+			},
+			func() {}); err != nil {
+			return err
+		}
+	}
+}
+
+// nextButtonFinder returns the next button unless it's missing, hidden or disabled
+func (p *PageTemplate) nextButtonFinder(selector string) func(page int) *ElementTemplate {
+	return func(int) *ElementTemplate {
+		var button *ElementTemplate
+
+		_ = catch(func() {
+			if !p.Has(selector) {
+				return
+			}
+
+			el := p.El(selector)
+			if isClickable(el) {
+				button = el
+			}
+		})
+
+		return button
+	}
+}
+
+func isClickable(el *ElementTemplate) bool {
+	clickable := false
+
+	_ = catch(func() {
+		if !el.MustVisible() {
+			return
+		}
+
+		if disabled := el.MustAttribute("disabled"); disabled != nil {
+			return
+		}
+
+		if ariaDisabled := el.MustAttribute("aria-disabled"); ariaDisabled != nil && *ariaDisabled == "true" {
+			return
+		}
+
+		clickable = true
+	})
+
+	return clickable
+}
+
+// pageLinkFinder returns the link for the page after current one
+func (p *PageTemplate) pageLinkFinder(linkSelector string) func(page int) *ElementTemplate {
+	return func(page int) *ElementTemplate {
+		next := strconv.Itoa(page + 1)
+
+		links, err := p.items(linkSelector)
+		if err != nil {
+			return nil
+		}
+
+		for _, link := range links {
+			text, errText := link.Text()
+			if errText != nil {
+				continue
+			}
+
+			if strings.TrimSpace(text) == next && isClickable(link) {
+				return link
+			}
+		}
+
+		return nil
+	}
+}
+
+func (p *PageTemplate) paginateByURL(ctx context.Context, opt PaginateOption, f PaginateFunc) error {
+	base, err := url.Parse(p.URL())
+	if err != nil {
+		return err
+	}
+
+	start := opt.StartPage
+	if start == 0 {
+		start = 1
+	}
+
+	prev := ""
+
+	for page := 1; ; page++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		u := *base
+		q := u.Query()
+		q.Set(opt.URLParam, strconv.Itoa(start+page-1))
+		u.RawQuery = q.Encode()
+
+		if errCatch := catch(func() { err = p.Navigate(u.String()) }); errCatch != nil {
+			return errCatch
+		} else if err != nil {
+			return err
+		}
+
+		items, errItems := p.items(opt.ItemSelector)
+		if errItems != nil {
+			return errItems
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		// a site may show the last page for a page number out of range
+		signature := p.itemsSignature(opt.ItemSelector)
+		if signature != "" && signature == prev {
+			p.logger().Debug("paginate stopped at the same items as the previous page", "page", page, "url", u.String())
+			return nil
+		}
+		prev = signature
+
+		if err = f(page, items); err != nil {
+			return err
+		}
+
+		if reachedMaxPages(opt, page) {
+			return nil
+		}
+	}
+}
+
+func (p *PageTemplate) paginateByScroll(ctx context.Context, opt PaginateOption, f PaginateFunc) error {
+	seen := 0
+
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, err := p.items(opt.ItemSelector)
+		if err != nil {
+			return err
+		}
+
+		if len(items) <= seen {
+			return nil
+		}
+
+		if err = f(page, items[seen:]); err != nil {
+			return err
+		}
+		seen = len(items)
+
+		if reachedMaxPages(opt, page) {
+			return nil
+		}
+
+		if err = catch(func() {
+			if opt.HumanScroll {
+				p.ScrollBottomHuman()
+			} else {
+				p.ScrollBottom()
+			}
+		}); err != nil {
+			return err
+		}
+
+		// no more items is the end of scroll rather than a failure
+		_ = waitFor(p, fmt.Sprintf("more than %d of %s", seen, opt.ItemSelector), opt.WaitTimeout, 100*time.Millisecond,
+			func() bool {
+				more, errMore := p.items(opt.ItemSelector)
+				return ctx.Err() != nil || (errMore == nil && len(more) > seen)
+			},
+			func() {})
+	}
+}
@@ -0,0 +1,115 @@
+// Package prommetrics exports measurements of rodtemplate as prometheus metrics.
+//
+//	m, err := prommetrics.New(prometheus.DefaultRegisterer, prommetrics.Option{})
+//	...
+//	restore := rodtemplate.SetDefaultMetrics(m)
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/darimuri/go-lib/rodtemplate"
+)
+
+var _ rodtemplate.Metrics = (*Metrics)(nil)
+
+type Option struct {
+	// Namespace prefixes metric names, defaults to rod
+	Namespace string
+	// DurationBuckets of wait and login durations in seconds, defaults to 0.1s to about 3m
+	DurationBuckets []float64
+	// SizeBuckets of screenshot sizes in bytes, defaults to 16KiB to 16MiB
+	SizeBuckets []float64
+}
+
+// Metrics has metrics labeled by kind of polling function, site of the page and outcome.
+// Selectors and wait targets are not labels to keep cardinality low.
+type Metrics struct {
+	waitDuration    *prometheus.HistogramVec
+	waitRetries     *prometheus.CounterVec
+	waitTimeouts    *prometheus.CounterVec
+	logins          *prometheus.CounterVec
+	loginDuration   *prometheus.HistogramVec
+	screenShotBytes *prometheus.HistogramVec
+}
+
+// New registers metrics to reg
+func New(reg prometheus.Registerer, opt Option) (*Metrics, error) {
+	if opt.Namespace == "" {
+		opt.Namespace = "rod"
+	}
+
+	if opt.DurationBuckets == nil {
+		opt.DurationBuckets = prometheus.ExponentialBuckets(0.1, 2, 12)
+	}
+
+	if opt.SizeBuckets == nil {
+		opt.SizeBuckets = prometheus.ExponentialBuckets(16*1024, 4, 6)
+	}
+
+	m := &Metrics{
+		waitDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opt.Namespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Duration of polling loops like WaitFor and ClickWhenAvailable.",
+			Buckets:   opt.DurationBuckets,
+		}, []string{"kind", "site", "outcome"}),
+		waitRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opt.Namespace,
+			Name:      "wait_retries_total",
+			Help:      "Attempts after the first one of polling loops.",
+		}, []string{"kind", "site"}),
+		waitTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opt.Namespace,
+			Name:      "wait_timeouts_total",
+			Help:      "Polling loops ended without finding the target.",
+		}, []string{"kind", "site"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opt.Namespace,
+			Name:      "login_attempts_total",
+			Help:      "Logins by outcome.",
+		}, []string{"site", "outcome"}),
+		loginDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opt.Namespace,
+			Name:      "login_duration_seconds",
+			Help:      "Duration of logins by outcome.",
+			Buckets:   opt.DurationBuckets,
+		}, []string{"site", "outcome"}),
+		screenShotBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opt.Namespace,
+			Name:      "screenshot_size_bytes",
+			Help:      "Size of captured screenshots.",
+			Buckets:   opt.SizeBuckets,
+		}, []string{"site", "format"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.waitDuration, m.waitRetries, m.waitTimeouts, m.logins, m.loginDuration, m.screenShotBytes} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) ObserveWait(o rodtemplate.WaitObservation) {
+	outcome := "found"
+	if o.TimedOut {
+		outcome = "timeout"
+		m.waitTimeouts.WithLabelValues(o.Kind, o.Site).Inc()
+	}
+
+	m.waitDuration.WithLabelValues(o.Kind, o.Site, outcome).Observe(o.Elapsed.Seconds())
+	m.waitRetries.WithLabelValues(o.Kind, o.Site).Add(float64(o.Retries()))
+}
+
+func (m *Metrics) ObserveLogin(site string, outcome rodtemplate.LoginOutcome, elapsed time.Duration) {
+	m.logins.WithLabelValues(site, string(outcome)).Inc()
+	m.loginDuration.WithLabelValues(site, string(outcome)).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObserveScreenShot(site string, format string, size int) {
+	m.screenShotBytes.WithLabelValues(site, format).Observe(float64(size))
+}
@@ -0,0 +1,55 @@
+package prommetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/darimuri/go-lib/rodtemplate"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	m, err := New(reg, Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = New(reg, Option{}); err == nil {
+		t.Error("Expecting error registering twice")
+	}
+
+	defer rodtemplate.SetDefaultMetrics(m)()
+
+	count := 0
+	if err = rodtemplate.WaitFor("ready", time.Second, time.Millisecond, func() bool {
+		count++
+		return count == 3
+	}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = rodtemplate.WaitFor("never", 5*time.Millisecond, time.Millisecond, func() bool { return false }, func() {})
+
+	if retries := testutil.ToFloat64(m.waitRetries.WithLabelValues(rodtemplate.WaitKindWaitFor, "")); retries < 3 {
+		t.Errorf("Expecting at least 3 retries, got %v", retries)
+	}
+
+	if timeouts := testutil.ToFloat64(m.waitTimeouts.WithLabelValues(rodtemplate.WaitKindWaitFor, "")); timeouts != 1 {
+		t.Errorf("Expecting 1 timeout, got %v", timeouts)
+	}
+
+	m.ObserveLogin("example.com", rodtemplate.LoginRejected, time.Second)
+	m.ObserveScreenShot("example.com", "png", 1024)
+
+	if logins := testutil.ToFloat64(m.logins.WithLabelValues("example.com", "rejected")); logins != 1 {
+		t.Errorf("Expecting 1 rejected login, got %v", logins)
+	}
+
+	if n := testutil.CollectAndCount(m.screenShotBytes, "rod_screenshot_size_bytes"); n != 1 {
+		t.Errorf("Expecting screenshot size observed, got %d", n)
+	}
+}
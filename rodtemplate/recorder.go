@@ -0,0 +1,392 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+)
+
+// kinds of RecordedStep
+const (
+	StepNavigate = "navigate"
+	StepClick    = "click"
+	StepInput    = "input"
+	StepPress    = "press"
+)
+
+// RecordedStep is an action of the user captured by Recorder
+type RecordedStep struct {
+	Kind string `json:"kind"`
+	// URL is the url navigated to
+	URL string `json:"url,omitempty"`
+	// Selector is generated to match only the element acted on
+	Selector string `json:"selector,omitempty"`
+	// Value is the value typed in or the key pressed, empty if Secret
+	Value string `json:"value,omitempty"`
+	// Secret is an input of a password field, its value is not recorded
+	Secret bool      `json:"secret,omitempty"`
+	At     time.Time `json:"-"`
+}
+
+// NavigationGrace is how long a navigation after a click or a key press is taken as caused by it and not recorded
+var NavigationGrace = 3 * time.Second
+
+// recorderJS captures clicks, changed inputs and enter keys in the top frame and sends them to window[name].
+// Selectors prefer a unique id, data-testid, name or class and fall back to a nth-of-type path
+// from the closest ancestor having an id.
+const recorderJS = `(name) => {
+  if (window !== window.top || window.__rodRecorder) return;
+  window.__rodRecorder = true;
+
+  const unique = (sel) => {
+    try { return document.querySelectorAll(sel).length === 1; } catch (e) { return false; }
+  };
+  const attr = (el, a) => {
+    const v = el.getAttribute(a);
+    return v ? el.tagName.toLowerCase() + '[' + a + '=' + JSON.stringify(v) + ']' : '';
+  };
+  const step = (el) => {
+    const tag = el.tagName.toLowerCase();
+    if (el.id && !/^\d|\s/.test(el.id)) return '#' + CSS.escape(el.id);
+    const parent = el.parentElement;
+    if (!parent) return tag;
+    const same = Array.from(parent.children).filter((c) => c.tagName === el.tagName);
+    return same.length === 1 ? tag : tag + ':nth-of-type(' + (same.indexOf(el) + 1) + ')';
+  };
+  const selectorOf = (el) => {
+    if (el.id && !/^\d|\s/.test(el.id) && unique('#' + CSS.escape(el.id))) return '#' + CSS.escape(el.id);
+    for (const a of ['data-testid', 'data-test', 'name', 'aria-label']) {
+      const sel = attr(el, a);
+      if (sel && unique(sel)) return sel;
+    }
+    const classes = Array.from(el.classList).filter((c) => !/\d{3,}/.test(c)).map((c) => '.' + CSS.escape(c)).join('');
+    if (classes && unique(el.tagName.toLowerCase() + classes)) return el.tagName.toLowerCase() + classes;
+    const path = [];
+    for (let cur = el; cur && cur !== document.documentElement; cur = cur.parentElement) {
+      path.unshift(step(cur));
+      const sel = path.join(' > ');
+      if (cur.id && unique(sel)) return sel;
+    }
+    return 'html > ' + path.join(' > ');
+  };
+  const send = (kind, el, value, secret) => {
+    window[name]({kind: kind, selector: selectorOf(el), value: value || '', secret: !!secret});
+  };
+  const actionable = (el) => el.closest('a, button, input, select, textarea, label, summary, [role=button], [onclick]') || el;
+  const typeable = (el) => el.tagName === 'TEXTAREA' || el.isContentEditable ||
+    (el.tagName === 'INPUT' && !/^(button|submit|reset|checkbox|radio|file|image)$/.test(el.type));
+
+  document.addEventListener('click', (e) => {
+    const el = actionable(e.target);
+    if (typeable(el)) return;
+    send('click', el);
+  }, true);
+  document.addEventListener('change', (e) => {
+    const el = e.target;
+    if (el.tagName === 'SELECT' || typeable(el)) {
+      send('input', el, el.type === 'password' ? '' : el.value, el.type === 'password');
+    }
+  }, true);
+  document.addEventListener('keydown', (e) => {
+    if (e.key !== 'Enter' || !typeable(e.target) || e.target.tagName === 'TEXTAREA') return;
+    const el = e.target;
+    send('input', el, el.type === 'password' ? '' : el.value, el.type === 'password');
+    send('press', el, 'Enter');
+  }, true);
+}`
+
+// Recorder captures clicks, inputs and navigations of the user on a page, see PageTemplate.Record
+type Recorder struct {
+	p     *PageTemplate
+	mu    sync.Mutex
+	steps []RecordedStep
+	stops []func() error
+}
+
+// Record starts capturing clicks, inputs and navigations of the user on the page until Stop is called.
+// It is meant for a headful browser, actions in iframes and other pages are not captured.
+func (p *PageTemplate) Record() (*Recorder, error) {
+	r := &Recorder{p: p}
+
+	name := "__rodRecord"
+
+	stopExpose, err := p.P.Expose(name, func(payload gson.JSON) (interface{}, error) {
+		step := RecordedStep{}
+		if err := json.Unmarshal([]byte(payload.JSON("", "")), &step); err != nil {
+			return nil, err
+		}
+		r.add(step)
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.stops = append(r.stops, stopExpose)
+
+	code := fmt.Sprintf("(%s)(%q)", recorderJS, name)
+
+	removeScript, err := p.P.EvalOnNewDocument(code)
+	if err != nil {
+		_ = r.Stop()
+		return nil, err
+	}
+	r.stops = append(r.stops, removeScript)
+
+	if _, err = p.P.Evaluate(rod.Eval(recorderJS, name)); err != nil {
+		_ = r.Stop()
+		return nil, err
+	}
+
+	page, cancel := p.P.WithCancel()
+	r.stops = append(r.stops, func() error {
+		cancel()
+		return nil
+	})
+
+	go page.EachEvent(func(e *proto.PageFrameNavigated) {
+		if e.Frame.ParentID == "" {
+			r.add(RecordedStep{Kind: StepNavigate, URL: e.Frame.URL})
+		}
+	})()
+
+	if info, errInfo := p.P.Info(); errInfo == nil {
+		r.add(RecordedStep{Kind: StepNavigate, URL: info.URL})
+	}
+
+	p.logger().Info("recording started", "url", p.URL())
+
+	return r, nil
+}
+
+// add appends step merging it into the previous steps, it is called from event goroutines
+func (r *Recorder) add(step RecordedStep) {
+	if step.At.IsZero() {
+		step.At = time.Now()
+	}
+
+	r.p.logger().Debug("step recorded", "kind", step.Kind, "selector", step.Selector, "url", step.URL, "value", step.Value, "secret", step.Secret)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.steps = appendStep(r.steps, step)
+}
+
+// appendStep appends step to steps unless it is caused by or overrides the last step
+func appendStep(steps []RecordedStep, step RecordedStep) []RecordedStep {
+	if step.Kind == StepNavigate && (step.URL == "" || step.URL == "about:blank") {
+		return steps
+	}
+
+	if len(steps) == 0 {
+		return append(steps, step)
+	}
+
+	last := steps[len(steps)-1]
+
+	switch step.Kind {
+	case StepNavigate:
+		if last.Kind == StepNavigate && last.URL == step.URL {
+			return steps
+		}
+		if (last.Kind == StepClick || last.Kind == StepPress) && step.At.Sub(last.At) < NavigationGrace {
+			return steps
+		}
+	case StepInput:
+		// enter records the input before change does
+		if last.Kind == StepInput && last.Selector == step.Selector {
+			steps[len(steps)-1] = step
+			return steps
+		}
+		if len(steps) > 1 && last.Kind == StepPress && last.Selector == step.Selector {
+			prev := steps[len(steps)-2]
+			if prev.Kind == StepInput && prev.Selector == step.Selector && prev.Value == step.Value {
+				return steps
+			}
+		}
+	}
+
+	return append(steps, step)
+}
+
+// Steps returns the steps captured so far
+func (r *Recorder) Steps() []RecordedStep {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	steps := make([]RecordedStep, len(r.steps))
+	copy(steps, r.steps)
+
+	return steps
+}
+
+// Stop stops capturing and removes the listener from new documents
+func (r *Recorder) Stop() error {
+	var errs []string
+	for i := len(r.stops) - 1; i >= 0; i-- {
+		if err := r.stops[i](); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	r.stops = nil
+
+	r.p.logger().Info("recording stopped", "steps", len(r.Steps()))
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// GenerateOption tells how GenerateGo writes steps
+type GenerateOption struct {
+	// Package of the generated file, main if empty
+	Package string
+	// Func is the name of the generated func taking pt *rodtemplate.PageTemplate, Scenario if empty
+	Func string
+	// Timeout of WaitUntilHas before clicks, 10s if 0
+	Timeout time.Duration
+}
+
+// GenerateGo writes steps as a go file of a func running them with PageTemplate.
+// Values of secret inputs are taken as parameters of the func named secret1, secret2 and so on.
+func GenerateGo(w io.Writer, steps []RecordedStep, opt GenerateOption) error {
+	if opt.Package == "" {
+		opt.Package = "main"
+	}
+	if opt.Func == "" {
+		opt.Func = "Scenario"
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+
+	data := generateData{GenerateOption: opt}
+	for _, s := range steps {
+		g := generatedStep{RecordedStep: s, Value: strconv.Quote(s.Value)}
+
+		switch s.Kind {
+		case StepClick:
+			data.UsesFmt, data.UsesTime = true, true
+		case StepPress:
+			data.UsesInput = true
+			if s.Value != "Enter" {
+				return fmt.Errorf("unsupported key %q", s.Value)
+			}
+		case StepInput:
+			if s.Secret {
+				g.Value = fmt.Sprintf("secret%d", len(data.Secrets)+1)
+				data.Secrets = append(data.Secrets, g.Value)
+			}
+		case StepNavigate:
+		default:
+			return fmt.Errorf("unsupported step %q", s.Kind)
+		}
+
+		data.Steps = append(data.Steps, g)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := generateTemplate.Execute(buf, data); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
+
+// GenerateGo writes the steps captured so far as a go file, see GenerateGo
+func (r *Recorder) GenerateGo(w io.Writer, opt GenerateOption) error {
+	return GenerateGo(w, r.Steps(), opt)
+}
+
+type generateData struct {
+	GenerateOption
+	Steps     []generatedStep
+	Secrets   []string
+	UsesFmt   bool
+	UsesTime  bool
+	UsesInput bool
+}
+
+type generatedStep struct {
+	RecordedStep
+	// Value is a go expression of the value
+	Value string
+}
+
+var generateTemplate = template.Must(template.New("scenario").Funcs(template.FuncMap{
+	"quote":    strconv.Quote,
+	"duration": durationExpr,
+}).Parse(`// Code generated by rodtemplate.Recorder. Edit as needed.
+
+package {{.Package}}
+
+import (
+{{- if .UsesFmt}}
+	"fmt"
+{{- end}}
+{{- if .UsesTime}}
+	"time"
+{{- end}}
+{{if .UsesInput}}
+	"github.com/go-rod/rod/lib/input"
+{{- end}}
+
+	"github.com/darimuri/go-lib/rodtemplate"
+)
+
+func {{.Func}}(pt *rodtemplate.PageTemplate{{range .Secrets}}, {{.}}{{end}}{{if .Secrets}} string{{end}}) error {
+{{- range .Steps}}
+{{- if eq .Kind "navigate"}}
+	if err := pt.Navigate({{quote .URL}}); err != nil {
+		return err
+	}
+{{- else if eq .Kind "click"}}
+	if !pt.WaitUntilHas({{quote .Selector}}, 100*time.Millisecond, {{duration $.Timeout}}) {
+		return fmt.Errorf("%s is not found", {{quote .Selector}})
+	}
+	pt.ClickElement({{quote .Selector}})
+{{- else if eq .Kind "input"}}
+	pt.Input({{quote .Selector}}, {{.Value}})
+{{- else if eq .Kind "press"}}
+	pt.PressKey(input.{{.RecordedStep.Value}})
+{{- end}}
+{{- end}}
+
+	return nil
+}
+`))
+
+// durationExpr returns a go expression of d like 10 * time.Second
+func durationExpr(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d*time.Hour", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d*time.Minute", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d*time.Second", d/time.Second)
+	}
+
+	return fmt.Sprintf("%d*time.Millisecond", d/time.Millisecond)
+}
@@ -0,0 +1,115 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendStep(t *testing.T) {
+	now := time.Now()
+
+	steps := []RecordedStep(nil)
+	for _, s := range []RecordedStep{
+		{Kind: StepNavigate, URL: "about:blank", At: now},
+		{Kind: StepNavigate, URL: "https://example.com/login", At: now},
+		{Kind: StepNavigate, URL: "https://example.com/login", At: now},
+		{Kind: StepInput, Selector: "#id", Value: "m", At: now},
+		{Kind: StepInput, Selector: "#id", Value: "me", At: now},
+		{Kind: StepInput, Selector: "#password", Secret: true, At: now},
+		{Kind: StepPress, Selector: "#password", Value: "Enter", At: now},
+		{Kind: StepInput, Selector: "#password", Secret: true, At: now},
+		{Kind: StepNavigate, URL: "https://example.com/home", At: now.Add(time.Second)},
+		{Kind: StepClick, Selector: "a.logout", At: now.Add(10 * time.Second)},
+		{Kind: StepNavigate, URL: "https://example.com/", At: now.Add(20 * time.Second)},
+	} {
+		steps = appendStep(steps, s)
+	}
+
+	expected := []string{"navigate https://example.com/login", "input #id me", "input #password ", "press #password Enter", "click a.logout ", "navigate https://example.com/"}
+	if len(steps) != len(expected) {
+		t.Fatalf("Expecting %d steps, got %+v", len(expected), steps)
+	}
+
+	for i, s := range steps {
+		actual := s.Kind + " " + s.Selector + s.URL
+		if s.Kind != StepNavigate {
+			actual += " " + s.Value
+		}
+
+		if actual != expected[i] {
+			t.Errorf("Expecting %q at %d, got %q", expected[i], i, actual)
+		}
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := GenerateGo(buf, []RecordedStep{
+		{Kind: StepNavigate, URL: "https://example.com/login"},
+		{Kind: StepInput, Selector: `input[name="id"]`, Value: "me"},
+		{Kind: StepInput, Selector: "#password", Secret: true},
+		{Kind: StepPress, Selector: "#password", Value: "Enter"},
+		{Kind: StepClick, Selector: "a.logout"},
+	}, GenerateOption{Package: "scenario", Func: "Login", Timeout: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := buf.String()
+	if _, err = parser.ParseFile(token.NewFileSet(), "login.go", src, 0); err != nil {
+		t.Fatalf("Expecting valid go, got %v\n%s", err, src)
+	}
+
+	for _, expected := range []string{
+		"package scenario",
+		"func Login(pt *rodtemplate.PageTemplate, secret1 string) error {",
+		`pt.Navigate("https://example.com/login")`,
+		`pt.Input("input[name=\"id\"]", "me")`,
+		`pt.Input("#password", secret1)`,
+		"pt.PressKey(input.Enter)",
+		`pt.WaitUntilHas("a.logout", 100*time.Millisecond, 1500*time.Millisecond)`,
+		`pt.ClickElement("a.logout")`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("Expecting %s in\n%s", expected, src)
+		}
+	}
+
+	if err = GenerateGo(buf, []RecordedStep{{Kind: "scroll"}}, GenerateOption{}); err == nil {
+		t.Error("Expecting error for unknown step")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	s, _, pt := openFixture(t, "login.html")
+
+	rec, err := pt.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pt.Input("#id", "me")
+	pt.El("#id").MustBlur()
+	pt.ClickElement("#submit")
+	pt.WaitLoad()
+
+	// bindings are called back asynchronously
+	_ = pt.Retry(RetryPolicy{Timeout: 2 * time.Second}, "steps", Check(func() bool { return len(rec.Steps()) >= 3 }))
+
+	if err = rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := rec.Steps()
+	if len(steps) != 3 || steps[0].URL != s.URLOf("login.html") {
+		t.Fatalf("Expecting navigate, input and click, got %+v", steps)
+	}
+
+	if steps[1].Kind != StepInput || steps[1].Selector != "#id" || steps[1].Value != "me" || steps[2].Selector != "#submit" {
+		t.Errorf("Expecting input of #id and click of #submit, got %+v", steps[1:])
+	}
+}
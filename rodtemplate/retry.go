@@ -0,0 +1,224 @@
+package rodtemplate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Backoff returns the delay after the attempt, attempt starts from 1
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits d after every attempt
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff waits initial after the first attempt multiplying it by factor up to max, max is unlimited if 0
+func ExponentialBackoff(initial, max time.Duration, factor float64) Backoff {
+	return func(attempt int) time.Duration {
+		d := float64(initial) * math.Pow(factor, float64(attempt-1))
+		if max > 0 && (d > float64(max) || math.IsInf(d, 1)) {
+			return max
+		}
+
+		return time.Duration(d)
+	}
+}
+
+// WithJitter randomizes delays of b by up to fraction of them in both directions
+func WithJitter(b Backoff, fraction float64) Backoff {
+	return func(attempt int) time.Duration {
+		d := b(attempt)
+		jitter := (rand.Float64()*2 - 1) * fraction * float64(d)
+
+		if d = d + time.Duration(jitter); d < 0 {
+			return 0
+		}
+
+		return d
+	}
+}
+
+// RetryPolicy tells how long and how often a condition is checked
+type RetryPolicy struct {
+	// Backoff is the delay between attempts, 100ms if nil
+	Backoff Backoff
+	// MaxAttempts stops retrying after the number of attempts, unlimited if 0
+	MaxAttempts int
+	// Timeout stops retrying once it is elapsed from the first attempt, unlimited if 0.
+	// The last attempt is made at the deadline rather than sleeping beyond it.
+	Timeout time.Duration
+}
+
+// policies of polling methods, replace them to tune every call
+var (
+	// AvailablePolicy is the policy of ClickWhenAvailable and FocusWhenAvailable
+	AvailablePolicy = RetryPolicy{Backoff: ConstantBackoff(100 * time.Millisecond), MaxAttempts: 1000}
+	// InputPolicy is the policy of Input waiting for its element
+	InputPolicy = RetryPolicy{Backoff: ConstantBackoff(100 * time.Millisecond), MaxAttempts: 100}
+	// LoginInputPolicy is the policy of Login.Submit finding the login input in frames and pages
+	LoginInputPolicy = RetryPolicy{Backoff: ConstantBackoff(100 * time.Millisecond), MaxAttempts: 10}
+	// LoginSuccessPolicy is the policy of Login.Submit waiting for LoginSuccessSelector
+	LoginSuccessPolicy = RetryPolicy{Backoff: ConstantBackoff(100 * time.Millisecond), MaxAttempts: 100}
+)
+
+// Condition tells whether a wait is satisfied, an error stops retrying and is returned as is
+type Condition func() (bool, error)
+
+// Check makes a Condition of a func without error
+func Check(f func() bool) Condition {
+	return func() (bool, error) {
+		return f(), nil
+	}
+}
+
+// AllOf is satisfied when every condition is satisfied, checked in order until one is not
+func AllOf(conditions ...Condition) Condition {
+	return func() (bool, error) {
+		for _, c := range conditions {
+			if ok, err := c(); err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// AnyOf is satisfied when one of conditions is satisfied, checked in order until one is
+func AnyOf(conditions ...Condition) Condition {
+	return func() (bool, error) {
+		for _, c := range conditions {
+			if ok, err := c(); err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// Not is satisfied when condition is not
+func Not(condition Condition) Condition {
+	return func() (bool, error) {
+		ok, err := condition()
+		return !ok && err == nil, err
+	}
+}
+
+// Retry checks condition until it is satisfied following policy.
+// It returns *TimeoutError when the policy or ctx stops retrying, the error of condition if any.
+func Retry(ctx context.Context, policy RetryPolicy, target string, condition Condition) error {
+	_, err := (*PageTemplate)(nil).retry(ctx, WaitKindRetry, target, policy, condition, nil)
+	return err
+}
+
+// Retry checks condition until it is satisfied following policy in the context of the page.
+// It is logged, traced and measured with the logger, tracer provider and metrics of p.
+func (p *PageTemplate) Retry(policy RetryPolicy, target string, condition Condition) error {
+	_, err := p.retry(p.context(), WaitKindRetry, target, policy, condition, nil)
+	return err
+}
+
+func (p *PageTemplate) context() context.Context {
+	if p == nil || p.P == nil {
+		return context.Background()
+	}
+
+	return p.P.GetContext()
+}
+
+// retry is the engine of every polling method.
+// Attributes are set on span if given, a span named after kind is started otherwise.
+func (p *PageTemplate) retry(ctx context.Context, kind, target string, policy RetryPolicy, condition Condition, span trace.Span) (attempts int, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if span == nil {
+		span = p.startSpan("rodtemplate."+kind, AttrTarget.String(target), AttrTimeout.String(policy.Timeout.String()))
+		defer endSpan(span, &err)
+	}
+
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = ConstantBackoff(100 * time.Millisecond)
+	}
+
+	logger := p.logger()
+	started := time.Now()
+
+	defer func() {
+		span.SetAttributes(AttrAttempts.Int(attempts), AttrFound.Bool(err == nil))
+		p.observeWait(kind, target, attempts, started, err != nil)
+	}()
+
+	for {
+		attempts++
+
+		ok, errCondition := condition()
+		if errCondition != nil {
+			return attempts, errCondition
+		} else if ok {
+			return attempts, nil
+		}
+
+		elapsed := time.Since(started)
+
+		if policy.MaxAttempts > 0 && attempts >= policy.MaxAttempts {
+			return attempts, p.timeout(kind, target, policy, started, attempts, nil)
+		}
+
+		if policy.Timeout > 0 && elapsed >= policy.Timeout {
+			return attempts, p.timeout(kind, target, policy, started, attempts, nil)
+		}
+
+		delay := backoff(attempts)
+		if policy.Timeout > 0 && elapsed+delay > policy.Timeout {
+			delay = policy.Timeout - elapsed
+		}
+
+		logger.Debug("retry after sleep", "kind", kind, "target", target, "attempt", attempts, "elapsed", elapsed, "sleep", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, p.timeout(kind, target, policy, started, attempts, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *PageTemplate) timeout(kind, target string, policy RetryPolicy, started time.Time, attempts int, cause error) *TimeoutError {
+	elapsed := time.Since(started)
+
+	var message string
+	switch {
+	case cause != nil:
+		message = fmt.Sprintf("%s after %d attempts in %s waiting for %s", cause, attempts, elapsed, target)
+	case policy.MaxAttempts > 0 && attempts >= policy.MaxAttempts:
+		message = fmt.Sprintf("%d attempts exceeded in %s waiting for %s", attempts, elapsed, target)
+	default:
+		message = fmt.Sprintf("timeout %s exceeded after %d attempts waiting for %s", policy.Timeout, attempts, target)
+	}
+
+	p.logger().Debug("wait timed out", "kind", kind, "target", target, "attempts", attempts, "elapsed", elapsed, "error", cause)
+
+	return &TimeoutError{
+		Timout:   policy.Timeout,
+		Started:  started,
+		Message:  message,
+		Target:   target,
+		Attempts: attempts,
+		Elapsed:  elapsed,
+		Err:      cause,
+	}
+}
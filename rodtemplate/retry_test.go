@@ -0,0 +1,101 @@
+package rodtemplate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	exp := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, 2)
+	for attempt, expected := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 2000: 50 * time.Millisecond} {
+		if d := exp(attempt); d != expected {
+			t.Errorf("Expecting %s for attempt %d, got %s", expected, attempt, d)
+		}
+	}
+
+	jitter := WithJitter(ConstantBackoff(100*time.Millisecond), 0.2)
+	for i := 0; i < 100; i++ {
+		if d := jitter(1); d < 80*time.Millisecond || 120*time.Millisecond < d {
+			t.Fatalf("Expecting 80ms to 120ms, got %s", d)
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	yes, no := Check(func() bool { return true }), Check(func() bool { return false })
+	errBroken := errors.New("broken")
+	broken := func() (bool, error) { return false, errBroken }
+
+	cases := []struct {
+		name      string
+		condition Condition
+		ok        bool
+		err       error
+	}{
+		{"AllOf", AllOf(yes, yes), true, nil},
+		{"AllOf not", AllOf(yes, no, broken), false, nil},
+		{"AllOf error", AllOf(yes, broken), false, errBroken},
+		{"AnyOf", AnyOf(no, yes, broken), true, nil},
+		{"AnyOf not", AnyOf(no, no), false, nil},
+		{"Not", Not(no), true, nil},
+		{"Not error", Not(broken), false, errBroken},
+	}
+
+	for _, c := range cases {
+		if ok, err := c.condition(); ok != c.ok || err != c.err {
+			t.Errorf("Expecting %v, %v for %s, got %v, %v", c.ok, c.err, c.name, ok, err)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	fast := ConstantBackoff(time.Millisecond)
+
+	count := 0
+	if err := Retry(ctx, RetryPolicy{Backoff: fast, MaxAttempts: 5}, "third", Check(func() bool {
+		count++
+		return count == 3
+	})); err != nil || count != 3 {
+		t.Errorf("Expecting satisfied at 3rd attempt, got %d, %v", count, err)
+	}
+
+	var te *TimeoutError
+	err := Retry(ctx, RetryPolicy{Backoff: fast, MaxAttempts: 4}, "never", Check(func() bool { return false }))
+	if !errors.As(err, &te) || te.Attempts != 4 || te.Target != "never" || !te.Timeout() {
+		t.Errorf("Expecting TimeoutError after 4 attempts, got %v", err)
+	}
+
+	started := time.Now()
+	err = Retry(ctx, RetryPolicy{Backoff: ConstantBackoff(time.Hour), Timeout: 20 * time.Millisecond}, "deadline", Check(func() bool { return false }))
+	if !errors.As(err, &te) || te.Attempts != 2 || time.Since(started) > time.Second {
+		t.Errorf("Expecting last attempt at the deadline, got %v", err)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Retry(cancelCtx, RetryPolicy{Backoff: ConstantBackoff(time.Hour)}, "canceled", Check(func() bool { return false }))
+	if !errors.As(err, &te) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expecting TimeoutError of canceled context, got %v", err)
+	}
+
+	errBroken := errors.New("broken")
+	count = 0
+	err = Retry(ctx, RetryPolicy{Backoff: fast}, "broken", func() (bool, error) {
+		count++
+		return false, errBroken
+	})
+	if err != errBroken || count != 1 {
+		t.Errorf("Expecting condition error at first attempt, got %d, %v", count, err)
+	}
+
+	count = 0
+	if err = WaitFor("no timeout", 0, time.Hour, func() bool {
+		count++
+		return false
+	}, func() {}); !errors.As(err, &te) || count != 1 {
+		t.Errorf("Expecting one check without timeout, got %d, %v", count, err)
+	}
+}
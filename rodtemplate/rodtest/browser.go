@@ -0,0 +1,82 @@
+package rodtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// EnvBrowserBin overrides the browser binary looked up from the system
+const EnvBrowserBin = "ROD_BROWSER_BIN"
+
+// BrowserBin returns the browser binary to launch, false if there is none installed.
+// Browsers are never downloaded so that tests stay offline.
+func BrowserBin() (string, bool) {
+	if bin := os.Getenv(EnvBrowserBin); bin != "" {
+		return bin, true
+	}
+
+	return launcher.LookPath()
+}
+
+// Browser launches a headless browser closed on cleanup of t, t is skipped if no browser is installed
+func Browser(t testing.TB) *rod.Browser {
+	t.Helper()
+
+	bin, ok := BrowserBin()
+	if !ok {
+		t.Skipf("no browser is installed, set %s to run", EnvBrowserBin)
+	}
+
+	l := launcher.New().Bin(bin).Headless(true).NoSandbox(os.Geteuid() == 0)
+
+	controlURL, err := l.Launch()
+	if err != nil {
+		t.Fatalf("failed to launch browser %s: %v", bin, err)
+	}
+
+	b := rod.New().ControlURL(controlURL)
+	if err = b.Connect(); err != nil {
+		l.Kill()
+		t.Fatalf("failed to connect browser: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = b.Close()
+		l.Kill()
+		l.Cleanup()
+	})
+
+	return b
+}
+
+// Page opens url in b and waits for it to load
+func Page(t testing.TB, b *rod.Browser, url string) *rod.Page {
+	t.Helper()
+
+	p, err := b.Page(proto.TargetCreateTarget{URL: url})
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", url, err)
+	}
+
+	if err = p.WaitLoad(); err != nil {
+		t.Fatalf("failed to load %s: %v", url, err)
+	}
+
+	return p
+}
+
+// Setup starts a fixture server and a browser both closed on cleanup of t
+func Setup(t testing.TB) (*Server, *rod.Browser) {
+	t.Helper()
+
+	b := Browser(t)
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+
+	return s, b
+}
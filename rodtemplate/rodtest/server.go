@@ -0,0 +1,104 @@
+// Package rodtest serves local fixture pages and launches a headless browser
+// so that page templates can be tested without network access.
+package rodtest
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+const (
+	DefaultID       = "user"
+	DefaultPassword = "password"
+
+	sessionCookie = "rodtest-session"
+)
+
+// Server serves fixtures and a fake login backend.
+//
+//	/gate.html    login link to /login.html
+//	/login.html   login form, ?target=_top or ?target=main sets target of the form
+//	/iframe.html  login form in an iframe
+//	/popup.html   button opening login form in a popup
+//	/home         welcome page, redirects to /login.html before login
+//	/scroll.html  infinite scroll of 100 items, 20 at a time
+//	/pages.html   3 pages of 5 items with numbered and next links, ?page=N, ?clamp=1 for the last page beyond it
+//	/table.html   table with rowspan and colspan, div grid and a product block
+type Server struct {
+	*httptest.Server
+
+	ID       string
+	Password string
+}
+
+// NewServer starts a fixture server accepting DefaultID and DefaultPassword, close it after use
+func NewServer() *Server {
+	s := &Server{ID: DefaultID, Password: DefaultPassword}
+
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", s.handleLogin)
+	mux.HandleFunc("/logout", s.handleLogout)
+	mux.HandleFunc("/home", s.handleHome)
+	mux.Handle("/", http.FileServer(http.FS(sub)))
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// URLOf returns absolute url of path on the server
+func (s *Server) URLOf(path string) string {
+	return s.URL + "/" + strings.TrimPrefix(path, "/")
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/login.html", http.StatusSeeOther)
+		return
+	}
+
+	if r.FormValue("id") != s.ID || r.FormValue("password") != s.Password {
+		// back to the same login url so that a failed login is detected by url
+		back := r.Referer()
+		if back == "" {
+			back = "/login.html"
+		}
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: s.ID, Path: "/"})
+	http.Redirect(w, r, "/home", http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/gate.html", http.StatusSeeOther)
+}
+
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(sessionCookie); err != nil || c.Value != s.ID {
+		http.Redirect(w, r, "/login.html", http.StatusSeeOther)
+		return
+	}
+
+	b, err := fixtures.ReadFile("fixtures/home.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,80 @@
+package rodtest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func noRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func TestServerFixtures(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	for _, path := range []string{"gate.html", "login.html", "iframe.html", "popup.html", "scroll.html", "pages.html", "table.html", "conditions.html", "visual.html", "tall.html", "noscroll.html"} {
+		res, err := http.Get(s.URLOf(path))
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", path, err)
+		}
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Expecting 200 for %s, got %d", path, res.StatusCode)
+		}
+	}
+}
+
+func TestServerLogin(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := &http.Client{CheckRedirect: noRedirect}
+
+	res, err := client.Get(s.URLOf("/home"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if location := res.Header.Get("Location"); location != "/login.html" {
+		t.Errorf("Expecting redirect to login before login, got %s", location)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, s.URLOf("/login"), strings.NewReader(url.Values{
+		"id":       {DefaultID},
+		"password": {"wrong"},
+	}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", s.URLOf("/login.html"))
+
+	res, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if location := res.Header.Get("Location"); location != s.URLOf("/login.html") {
+		t.Errorf("Expecting redirect back to login on wrong password, got %s", location)
+	}
+
+	res, err = client.PostForm(s.URLOf("/login"), url.Values{
+		"id":       {DefaultID},
+		"password": {DefaultPassword},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if location := res.Header.Get("Location"); location != "/home" {
+		t.Errorf("Expecting redirect to home, got %s", location)
+	}
+
+	if len(res.Cookies()) != 1 {
+		t.Errorf("Expecting session cookie, got %v", res.Cookies())
+	}
+}
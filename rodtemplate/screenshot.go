@@ -0,0 +1,238 @@
+package rodtemplate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultQuality is the quality of jpeg and webp screenshots not having one
+const DefaultQuality = 95
+
+type ScreenShotOption struct {
+	// Format is png, jpeg or webp, png if empty
+	Format proto.PageCaptureScreenshotFormat
+	// Quality of jpeg and webp from 1 to 100, DefaultQuality if 0. It is ignored for png which is lossless.
+	Quality int
+	// Scale is the device scale factor of the image, 1 if 0. 2 captures twice as many pixels on each side like a retina display.
+	Scale float64
+	// OptimizeForSpeed encodes faster into a larger image, it helps png the most
+	OptimizeForSpeed bool
+
+	XDelta      float64
+	YDelta      float64
+	WidthDelta  float64
+	HeightDelta float64
+}
+
+// ScreenShotFormatOf returns the format of the extension of path, png if it is not .jpg, .jpeg or .webp
+func ScreenShotFormatOf(path string) proto.PageCaptureScreenshotFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		return proto.PageCaptureScreenshotFormatJpeg
+	case ".webp":
+		return proto.PageCaptureScreenshotFormatWebp
+	}
+
+	return proto.PageCaptureScreenshotFormatPng
+}
+
+// request returns the capture request of opt without clip
+func (opt ScreenShotOption) request() (*proto.PageCaptureScreenshot, error) {
+	req := &proto.PageCaptureScreenshot{Format: opt.Format, OptimizeForSpeed: opt.OptimizeForSpeed}
+
+	switch opt.Format {
+	case "":
+		req.Format = proto.PageCaptureScreenshotFormatPng
+	case proto.PageCaptureScreenshotFormatPng:
+	case proto.PageCaptureScreenshotFormatJpeg, proto.PageCaptureScreenshotFormatWebp:
+		quality := opt.Quality
+		if quality == 0 {
+			quality = DefaultQuality
+		}
+		if quality < 1 || 100 < quality {
+			return nil, fmt.Errorf("quality %d is out of 1 to 100", opt.Quality)
+		}
+		req.Quality = &quality
+	default:
+		return nil, fmt.Errorf("unsupported screenshot format %q", opt.Format)
+	}
+
+	if opt.Scale < 0 {
+		return nil, fmt.Errorf("negative scale %v", opt.Scale)
+	}
+
+	return req, nil
+}
+
+func (opt ScreenShotOption) scale() float64 {
+	if opt.Scale == 0 {
+		return 1
+	}
+
+	return opt.Scale
+}
+
+// ScreenShotBytes captures el, the viewport if nil, without writing it anywhere
+func (p *PageTemplate) ScreenShotBytes(el *ElementTemplate, opt ScreenShotOption) (b []byte, err error) {
+	span := p.startSpan("rodtemplate.ScreenShot", attribute.String("rod.screenshot.format", string(opt.Format)))
+	defer endSpan(span, &err)
+
+	return p.captureElement(el, opt)
+}
+
+// ScreenShotFullBytes captures the whole page with the viewport resized to the content
+func (p *PageTemplate) ScreenShotFullBytes(opt ScreenShotOption) (b []byte, err error) {
+	span := p.startSpan("rodtemplate.ScreenShotFull", attribute.String("rod.screenshot.format", string(opt.Format)))
+	defer endSpan(span, &err)
+
+	err = p.fullViewport(func() error {
+		el, errEl := p.P.Element("html")
+		if errEl != nil {
+			return errEl
+		}
+
+		b, err = p.captureElement(p.Wrap(el), opt)
+		return err
+	})
+
+	return b, err
+}
+
+// WriteScreenShot captures el, the viewport if nil, into w
+func (p *PageTemplate) WriteScreenShot(w io.Writer, el *ElementTemplate, opt ScreenShotOption) error {
+	b, err := p.ScreenShotBytes(el, opt)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// WriteScreenShotFull captures the whole page into w like ScreenShotFullBytes
+func (p *PageTemplate) WriteScreenShotFull(w io.Writer, opt ScreenShotOption) error {
+	b, err := p.ScreenShotFullBytes(opt)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// saveScreenShot writes the html element into dumpPath in the format of its extension
+func (p *PageTemplate) saveScreenShot(dumpPath string) error {
+	html, err := p.P.Element("html")
+	if err != nil {
+		return err
+	}
+
+	b, err := p.ScreenShotBytes(p.Wrap(html), ScreenShotOption{Format: ScreenShotFormatOf(dumpPath)})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dumpPath, b, 0644)
+}
+
+// fullViewport runs f with the viewport resized to the content size and restores the viewport
+func (p *PageTemplate) fullViewport(f func() error) error {
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(p.P)
+	if err != nil {
+		return err
+	}
+
+	oldView := proto.EmulationSetDeviceMetricsOverride{}
+	set := p.P.LoadState(&oldView)
+	view := oldView
+	view.Width = int(metrics.ContentSize.Width)
+	view.Height = int(metrics.ContentSize.Height)
+
+	err = p.P.SetViewport(&view)
+	if err != nil {
+		return err
+	}
+
+	defer func() { // try to recover the viewport
+		if !set {
+			_ = proto.EmulationClearDeviceMetricsOverride{}.Call(p.P)
+			return
+		}
+
+		_ = p.P.SetViewport(&oldView)
+	}()
+
+	return f()
+}
+
+// captureElement captures the box of el moved and resized by deltas of opt, the viewport if el is nil
+func (p *PageTemplate) captureElement(el *ElementTemplate, opt ScreenShotOption) ([]byte, error) {
+	req, err := opt.request()
+	if err != nil {
+		return nil, err
+	}
+
+	if el == nil && opt.scale() != 1 {
+		view, errView := p.viewport()
+		if errView != nil {
+			return nil, errView
+		}
+		req.Clip = view
+	} else if el != nil {
+		if err = el.ScrollIntoView(); err != nil {
+			return nil, err
+		}
+
+		shape, errShape := el.Shape()
+		if errShape != nil {
+			return nil, errShape
+		}
+		if len(shape.Quads) == 0 {
+			return nil, errors.New("element has no box to capture")
+		}
+
+		quad := shape.Quads[0]
+
+		req.Clip = &proto.PageViewport{
+			X:      quad[0] + opt.XDelta,
+			Y:      quad[1] + opt.YDelta,
+			Width:  quad[2] - quad[0] + opt.WidthDelta,
+			Height: quad[7] - quad[1] + opt.HeightDelta,
+		}
+	}
+	if req.Clip != nil {
+		req.Clip.Scale = opt.scale()
+	}
+
+	byteArr, err := p.P.Screenshot(false, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if m := p.metrics(); m != NopMetrics {
+		m.ObserveScreenShot(p.site(), string(req.Format), len(byteArr))
+	}
+
+	return byteArr, nil
+}
+
+// viewport returns the visible area of the page in the coordinates of the document
+func (p *PageTemplate) viewport() (*proto.PageViewport, error) {
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(p.P)
+	if err != nil {
+		return nil, err
+	}
+
+	v := metrics.CSSVisualViewport
+
+	return &proto.PageViewport{X: v.PageX, Y: v.PageY, Width: v.ClientWidth, Height: v.ClientHeight}, nil
+}
@@ -0,0 +1,86 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestScreenShotFormatOf(t *testing.T) {
+	for path, expected := range map[string]proto.PageCaptureScreenshotFormat{
+		"a.png":        proto.PageCaptureScreenshotFormatPng,
+		"a.JPG":        proto.PageCaptureScreenshotFormatJpeg,
+		"a.jpeg":       proto.PageCaptureScreenshotFormatJpeg,
+		"dir/a.webp":   proto.PageCaptureScreenshotFormatWebp,
+		"no-extension": proto.PageCaptureScreenshotFormatPng,
+	} {
+		if format := ScreenShotFormatOf(path); format != expected {
+			t.Errorf("Expecting %s for %s, got %s", expected, path, format)
+		}
+	}
+}
+
+func TestScreenShotOptionRequest(t *testing.T) {
+	req, err := ScreenShotOption{Quality: 50}.request()
+	if err != nil || req.Format != proto.PageCaptureScreenshotFormatPng || req.Quality != nil {
+		t.Errorf("Expecting png without quality, got %+v, %v", req, err)
+	}
+
+	req, err = ScreenShotOption{Format: proto.PageCaptureScreenshotFormatWebp}.request()
+	if err != nil || req.Quality == nil || *req.Quality != DefaultQuality {
+		t.Errorf("Expecting webp of default quality, got %+v, %v", req, err)
+	}
+
+	req, err = ScreenShotOption{Format: proto.PageCaptureScreenshotFormatJpeg, Quality: 70, OptimizeForSpeed: true}.request()
+	if err != nil || *req.Quality != 70 || !req.OptimizeForSpeed {
+		t.Errorf("Expecting jpeg of quality 70 optimized for speed, got %+v, %v", req, err)
+	}
+
+	for _, invalid := range []ScreenShotOption{
+		{Format: "gif"},
+		{Format: proto.PageCaptureScreenshotFormatJpeg, Quality: 101},
+		{Scale: -1},
+	} {
+		if _, err = invalid.request(); err == nil {
+			t.Errorf("Expecting error for %+v", invalid)
+		}
+	}
+}
+
+func TestScreenShotBytes(t *testing.T) {
+	_, _, pt := openFixture(t, "visual.html")
+
+	b, err := pt.ScreenShotBytes(pt.El("#banner"), ScreenShotOption{Scale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 600 || bounds.Dy() != 200 {
+		t.Errorf("Expecting 600x200 for scale 2, got %v", bounds)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = pt.WriteScreenShot(buf, nil, ScreenShotOption{Format: proto.PageCaptureScreenshotFormatWebp, Quality: 80}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("RIFF")) {
+		t.Errorf("Expecting webp, got %q", buf.Bytes()[:8])
+	}
+
+	buf.Reset()
+	if err = pt.WriteScreenShotFull(buf, ScreenShotOption{Format: proto.PageCaptureScreenshotFormatJpeg}); err != nil || buf.Len() == 0 {
+		t.Errorf("Expecting full page jpeg, got %d bytes, %v", buf.Len(), err)
+	}
+
+	if _, err = pt.ScreenShotBytes(nil, ScreenShotOption{Format: "gif"}); err == nil {
+		t.Error("Expecting error for gif")
+	}
+}
@@ -0,0 +1,386 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+type TableOption struct {
+	// RowSelector selects rows of a div based grid, rows of <table> are used if empty
+	RowSelector string
+	// CellSelector selects cells in a row of a div based grid, defaults to "th, td"
+	CellSelector string
+	// HeaderRows is the number of leading header rows, detected from thead or th if 0
+	HeaderRows int
+	// NoHeader treats every row as data and names columns col1, col2, ...
+	NoHeader bool
+}
+
+// Table is a scraped table with rowspan and colspan expanded
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+type tableCell struct {
+	Text    string `json:"text"`
+	RowSpan int    `json:"rowSpan"`
+	ColSpan int    `json:"colSpan"`
+	Header  bool   `json:"header"`
+}
+
+type tableRow struct {
+	Cells  []tableCell `json:"cells"`
+	InHead bool        `json:"inHead"`
+}
+
+const tableJS = `function(rowSelector, cellSelector) {
+	const isTable = this.tagName === 'TABLE' && rowSelector === '';
+	const rows = isTable ? Array.from(this.rows) : Array.from(this.querySelectorAll(rowSelector || 'tr'));
+	return rows.map(row => {
+		const cells = isTable ? Array.from(row.cells) : Array.from(row.querySelectorAll(cellSelector));
+		return {
+			inHead: !!row.parentElement && row.parentElement.tagName === 'THEAD',
+			cells: cells.map(cell => ({
+				text: (cell.innerText || cell.textContent || '').trim(),
+				rowSpan: cell.rowSpan || parseInt(cell.getAttribute('rowspan')) || 1,
+				colSpan: cell.colSpan || parseInt(cell.getAttribute('colspan')) || 1,
+				header: cell.tagName === 'TH' || cell.getAttribute('role') === 'columnheader',
+			})),
+		};
+	});
+}`
+
+func (e ElementTemplate) Table() (*Table, error) {
+	return e.TableWithOption(TableOption{})
+}
+
+func (e ElementTemplate) TableWithOption(opt TableOption) (*Table, error) {
+	cellSelector := opt.CellSelector
+	if cellSelector == "" {
+		cellSelector = "th, td"
+	}
+
+	res, err := e.Eval(tableJS, opt.RowSelector, cellSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []tableRow
+	if err = res.Value.Unmarshal(&rows); err != nil {
+		return nil, err
+	}
+
+	return buildTable(rows, opt), nil
+}
+
+func buildTable(rows []tableRow, opt TableOption) *Table {
+	grid := expandSpans(rows)
+
+	headerRows := opt.HeaderRows
+	if opt.NoHeader {
+		headerRows = 0
+	} else if headerRows == 0 {
+		for _, r := range rows {
+			if !r.InHead {
+				break
+			}
+			headerRows++
+		}
+
+		if headerRows == 0 && len(rows) > 0 && isHeaderRow(rows[0]) {
+			headerRows = 1
+		}
+	}
+
+	if headerRows > len(grid) {
+		headerRows = len(grid)
+	}
+
+	width := 0
+	for _, r := range grid {
+		if len(r) > width {
+			width = len(r)
+		}
+	}
+
+	for i := range grid {
+		for len(grid[i]) < width {
+			grid[i] = append(grid[i], "")
+		}
+	}
+
+	return &Table{
+		Header: tableHeader(grid[:headerRows], width),
+		Rows:   grid[headerRows:],
+	}
+}
+
+func isHeaderRow(r tableRow) bool {
+	if len(r.Cells) == 0 {
+		return false
+	}
+
+	for _, c := range r.Cells {
+		if !c.Header {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandSpans copies a cell having rowspan or colspan into every position it covers
+func expandSpans(rows []tableRow) [][]string {
+	type carry struct {
+		text string
+		left int
+	}
+
+	grid := make([][]string, 0, len(rows))
+	carries := map[int]*carry{}
+
+	takeCarry := func(out []string, col int) ([]string, bool) {
+		c, ok := carries[col]
+		if !ok || c.left <= 0 {
+			return out, false
+		}
+
+		c.left--
+		if c.left == 0 {
+			delete(carries, col)
+		}
+
+		return append(out, c.text), true
+	}
+
+	for _, r := range rows {
+		out := make([]string, 0, len(r.Cells))
+
+		for _, cell := range r.Cells {
+			for {
+				var carried bool
+				if out, carried = takeCarry(out, len(out)); !carried {
+					break
+				}
+			}
+
+			colSpan := cell.ColSpan
+			if colSpan < 1 {
+				colSpan = 1
+			}
+
+			for i := 0; i < colSpan; i++ {
+				if cell.RowSpan > 1 {
+					carries[len(out)] = &carry{text: cell.Text, left: cell.RowSpan - 1}
+				}
+				out = append(out, cell.Text)
+			}
+		}
+
+		// trailing columns covered by rowspan of previous rows
+		for len(carries) > 0 {
+			var carried bool
+			if out, carried = takeCarry(out, len(out)); !carried {
+				break
+			}
+		}
+
+		grid = append(grid, out)
+	}
+
+	return grid
+}
+
+func tableHeader(headerRows [][]string, width int) []string {
+	header := make([]string, width)
+	seen := map[string]int{}
+
+	for col := 0; col < width; col++ {
+		parts := make([]string, 0, len(headerRows))
+		for _, r := range headerRows {
+			text := r[col]
+			if text == "" || (len(parts) > 0 && parts[len(parts)-1] == text) {
+				continue
+			}
+			parts = append(parts, text)
+		}
+
+		name := strings.Join(parts, " / ")
+		if name == "" {
+			name = fmt.Sprintf("col%d", col+1)
+		}
+
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+
+		header[col] = name
+	}
+
+	return header
+}
+
+// Records returns rows keyed by header
+func (t *Table) Records() []map[string]string {
+	records := make([]map[string]string, 0, len(t.Rows))
+	for _, r := range t.Rows {
+		record := make(map[string]string, len(t.Header))
+		for i, h := range t.Header {
+			record[h] = r[i]
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// Scan fills dst, a pointer to slice of struct, from rows.
+// Fields are matched by `table:"<header>"` tag or case-insensitively by field name,
+// and parse, strip, notrim options of `rod` tag are applied as in Extract.
+func (t *Table) Scan(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.New("scan destination must be a non nil pointer to slice")
+	}
+
+	sliceType := v.Elem().Type()
+	itemType := sliceType.Elem()
+	isPtr := itemType.Kind() == reflect.Ptr
+	if isPtr {
+		itemType = itemType.Elem()
+	}
+
+	if itemType.Kind() != reflect.Struct {
+		return fmt.Errorf("scan destination must be a slice of struct, got %s", sliceType)
+	}
+
+	type column struct {
+		field int
+		index int
+		opt   extractOption
+	}
+
+	columns := make([]column, 0)
+	for i := 0; i < itemType.NumField(); i++ {
+		sf := itemType.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, ok := sf.Tag.Lookup("table")
+		if name == "-" {
+			continue
+		}
+		if !ok {
+			name = sf.Name
+		}
+
+		index := -1
+		for idx, h := range t.Header {
+			if h == name || (!ok && strings.EqualFold(h, name)) {
+				index = idx
+				break
+			}
+		}
+
+		if index < 0 {
+			if ok {
+				return fmt.Errorf("column %s of field %s is not found in %v", name, sf.Name, t.Header)
+			}
+			continue
+		}
+
+		opt, err := parseExtractTag(sf.Tag.Get(ExtractTag))
+		if err != nil {
+			return fmt.Errorf("field %s: %s", sf.Name, err.Error())
+		}
+
+		columns = append(columns, column{field: i, index: index, opt: opt})
+	}
+
+	x := &extractor{}
+	slice := reflect.MakeSlice(sliceType, 0, len(t.Rows))
+
+	for rowIdx, r := range t.Rows {
+		item := reflect.New(itemType).Elem()
+
+		for _, c := range columns {
+			text := c.opt.stripAndTrim(r[c.index])
+			if err := setParsed(item.Field(c.field), text, c.opt); err != nil {
+				x.fail(fmt.Sprintf("[%d].%s", rowIdx, itemType.Field(c.field).Name), t.Header[c.index], err)
+			}
+		}
+
+		if isPtr {
+			slice = reflect.Append(slice, item.Addr())
+		} else {
+			slice = reflect.Append(slice, item)
+		}
+	}
+
+	v.Elem().Set(slice)
+
+	if len(x.errors) > 0 {
+		return &ExtractError{Fields: x.errors}
+	}
+
+	return nil
+}
+
+// WriteCSV writes header and rows as CSV
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(t.Header); err != nil {
+		return err
+	}
+
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// WriteJSONLines writes each row as a json object keyed by header in column order
+func (t *Table) WriteJSONLines(w io.Writer) error {
+	for _, r := range t.Rows {
+		buf := &bytes.Buffer{}
+		buf.WriteByte('{')
+
+		for i, h := range t.Header {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := json.Marshal(h)
+			if err != nil {
+				return err
+			}
+			val, err := json.Marshal(r[i])
+			if err != nil {
+				return err
+			}
+
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(val)
+		}
+
+		buf.WriteString("}\n")
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBuildTable(t *testing.T) {
+	cell := func(text string, rowSpan, colSpan int) tableCell {
+		return tableCell{Text: text, RowSpan: rowSpan, ColSpan: colSpan}
+	}
+
+	table := buildTable([]tableRow{
+		{InHead: true, Cells: []tableCell{cell("Name", 2, 1), cell("Score", 1, 2)}},
+		{InHead: true, Cells: []tableCell{cell("Math", 1, 1), cell("Art", 1, 1)}},
+		{Cells: []tableCell{cell("Kim", 2, 1), cell("1,000", 1, 1), cell("80", 1, 1)}},
+		{Cells: []tableCell{cell("90", 1, 1), cell("70", 1, 1)}},
+		{Cells: []tableCell{cell("Lee", 1, 1), cell("absent", 1, 2)}},
+	}, TableOption{})
+
+	expectHeader := []string{"Name", "Score / Math", "Score / Art"}
+	if !reflect.DeepEqual(table.Header, expectHeader) {
+		t.Errorf("Expecting %v, got %v", expectHeader, table.Header)
+	}
+
+	expectRows := [][]string{
+		{"Kim", "1,000", "80"},
+		{"Kim", "90", "70"},
+		{"Lee", "absent", "absent"},
+	}
+	if !reflect.DeepEqual(table.Rows, expectRows) {
+		t.Errorf("Expecting %v, got %v", expectRows, table.Rows)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := table.WriteJSONLines(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expectLine := `{"Name":"Kim","Score / Math":"1,000","Score / Art":"80"}`
+	if line, _ := buf.ReadString('\n'); line != expectLine+"\n" {
+		t.Errorf("Expecting %s, got %s", expectLine, line)
+	}
+
+	type score struct {
+		Name string
+		Math int `table:"Score / Math" rod:"strip=,"`
+	}
+
+	var scores []score
+	err := table.Scan(&scores)
+	if extractErr, ok := err.(*ExtractError); !ok || len(extractErr.Fields) != 1 {
+		t.Fatalf("Expecting one field error for absent, got %v", err)
+	}
+
+	if scores[0].Name != "Kim" || scores[0].Math != 1000 || scores[1].Math != 90 {
+		t.Errorf("Expecting scanned scores, got %v", scores)
+	}
+}
+
+func TestTable(t *testing.T) {
+	_, _, pt := openFixture(t, "table.html")
+
+	table, err := pt.El("#scores").Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 3 || table.Rows[1][0] != "Kim" || table.Rows[2][2] != "absent" {
+		t.Errorf("Expecting expanded rows, got %v", table.Rows)
+	}
+
+	grid, err := pt.El("#grid").TableWithOption(TableOption{RowSelector: ".row", CellSelector: ".cell"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(grid.Header, []string{"Product", "Price"}) || len(grid.Rows) != 2 {
+		t.Errorf("Expecting grid with header, got %v %v", grid.Header, grid.Rows)
+	}
+}
@@ -0,0 +1,252 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TiledOption tells how ScreenShotTiled scrolls and captures a page
+type TiledOption struct {
+	// Format is png or jpeg, png if empty. Quality and Scale are applied to the stitched image and tiles.
+	ScreenShotOption
+	// HideFixed hides fixed and sticky elements like headers after the first tile so that they are not repeated
+	HideFixed bool
+	// LazyWait is how long images in each tile are waited to be loaded, 5s if 0, not waited if negative
+	LazyWait time.Duration
+	// Delay is waited after each scroll for animations to settle
+	Delay time.Duration
+	// MaxTiles stops an endless page growing while scrolled, 100 if 0
+	MaxTiles int
+}
+
+// tile is a captured viewport and its offset in pixels of the stitched image
+type tile struct {
+	img image.Image
+	y   int
+}
+
+// lazyImagesJS is truthy when every image intersecting the viewport is loaded
+const lazyImagesJS = `Array.from(document.images).filter((img) => {
+	const r = img.getBoundingClientRect();
+	return r.width > 0 && r.bottom > 0 && r.top < window.innerHeight;
+}).every((img) => img.complete)`
+
+// ScreenShotTiled captures the whole page by scrolling it a viewport at a time and stitching the tiles.
+// Unlike ScreenShotFullBytes the viewport is not resized, so very tall pages and lazy loaded content are captured
+// as a user sees them.
+func (p *PageTemplate) ScreenShotTiled(opt TiledOption) (b []byte, err error) {
+	span := p.startSpan("rodtemplate.ScreenShotTiled", attribute.String("rod.screenshot.format", string(opt.Format)))
+	defer endSpan(span, &err)
+
+	if opt.Format != "" && opt.Format != proto.PageCaptureScreenshotFormatPng && opt.Format != proto.PageCaptureScreenshotFormatJpeg {
+		return nil, fmt.Errorf("unsupported format %q for stitched screenshot", opt.Format)
+	}
+
+	tiles, err := p.captureTiles(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("rod.screenshot.tiles", len(tiles)))
+
+	stitched := stitch(tiles)
+	buf := &bytes.Buffer{}
+
+	if opt.Format == proto.PageCaptureScreenshotFormatJpeg {
+		quality := opt.Quality
+		if quality == 0 {
+			quality = DefaultQuality
+		}
+		err = jpeg.Encode(buf, stitched, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(buf, stitched)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteScreenShotTiled writes the stitched screenshot of ScreenShotTiled into w
+func (p *PageTemplate) WriteScreenShotTiled(w io.Writer, opt TiledOption) error {
+	b, err := p.ScreenShotTiled(opt)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+func (p *PageTemplate) captureTiles(opt TiledOption) ([]tile, error) {
+	lazyWait := opt.LazyWait
+	if lazyWait == 0 {
+		lazyWait = 5 * time.Second
+	}
+
+	maxTiles := opt.MaxTiles
+	if maxTiles <= 0 {
+		maxTiles = 100
+	}
+
+	// tiles are decoded to be stitched, png keeps them lossless
+	tileOpt := ScreenShotOption{Format: proto.PageCaptureScreenshotFormatPng, Scale: opt.Scale}
+
+	origin, err := p.scrollY()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if opt.HideFixed {
+			if _, errShow := p.P.Eval(showFixedJS); errShow != nil {
+				p.logger().Warn("failed to show fixed elements", "error", errShow)
+			}
+		}
+		if _, errScroll := p.scrollTo(origin); errScroll != nil {
+			p.logger().Warn("failed to restore scroll", "error", errScroll)
+		}
+	}()
+
+	tiles := make([]tile, 0)
+	ratio := 0.0
+	y := 0.0
+	prev := 0.0
+
+	for len(tiles) < maxTiles {
+		actual, err := p.scrollTo(y)
+		if err != nil {
+			return nil, err
+		}
+
+		// overflow hidden or an inner scroll container keeps the window from scrolling though the content is taller
+		if len(tiles) > 0 && actual <= prev {
+			p.logger().Warn("stitched screenshot is cut as the page does not scroll", "scroll_y", actual, "tiles", len(tiles))
+			return tiles, nil
+		}
+		prev = actual
+
+		if len(tiles) == 1 && opt.HideFixed {
+			if _, err = p.P.Eval(hideFixedJS); err != nil {
+				return nil, err
+			}
+		}
+
+		if lazyWait > 0 {
+			if errWait := p.WaitUntil("lazy images", lazyWait, p.Cond().JS(lazyImagesJS)); errWait != nil {
+				p.logger().Warn("images are not loaded in time", "scroll_y", actual, "error", errWait)
+			}
+		}
+
+		if opt.Delay > 0 {
+			time.Sleep(opt.Delay)
+		}
+
+		shot, err := p.captureElement(nil, tileOpt)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err := png.Decode(bytes.NewReader(shot))
+		if err != nil {
+			return nil, err
+		}
+
+		metrics, err := proto.PageGetLayoutMetrics{}.Call(p.P)
+		if err != nil {
+			return nil, err
+		}
+		view := metrics.CSSLayoutViewport
+
+		if ratio == 0 {
+			ratio = float64(img.Bounds().Dx()) / float64(view.ClientWidth)
+		}
+
+		tiles = append(tiles, tile{img: img, y: int(math.Round(actual * ratio))})
+
+		// the content is measured again for lazy loaded content growing the page
+		if actual+float64(view.ClientHeight) >= metrics.CSSContentSize.Height {
+			return tiles, nil
+		}
+
+		y = actual + float64(view.ClientHeight)
+	}
+
+	p.logger().Warn("stitched screenshot is cut by max tiles", "max_tiles", maxTiles)
+
+	return tiles, nil
+}
+
+// stitch draws tiles at their offsets, a later tile covers the overlap of the former one
+func stitch(tiles []tile) image.Image {
+	width, height := 0, 0
+	for _, t := range tiles {
+		b := t.img.Bounds()
+		width = maxInt(width, b.Dx())
+		height = maxInt(height, t.y+b.Dy())
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, t := range tiles {
+		b := t.img.Bounds()
+		draw.Draw(canvas, image.Rect(0, t.y, b.Dx(), t.y+b.Dy()), t.img, b.Min, draw.Src)
+	}
+
+	return canvas
+}
+
+// scrollTo scrolls the window to y and returns the scroll it ends up with, which is less than y at the bottom
+func (p *PageTemplate) scrollTo(y float64) (float64, error) {
+	res, err := p.P.Eval(`(y) => { window.scrollTo(0, y); return window.scrollY }`, y)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = p.P.WaitRepaint(); err != nil {
+		return 0, err
+	}
+
+	return res.Value.Num(), nil
+}
+
+func (p *PageTemplate) scrollY() (float64, error) {
+	res, err := p.P.Eval(`() => window.scrollY`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Value.Num(), nil
+}
+
+// hideFixedJS hides fixed and sticky elements keeping their inline visibility to be restored by showFixedJS
+const hideFixedJS = `() => {
+	const hidden = [];
+	for (const el of document.querySelectorAll('body *')) {
+		const position = getComputedStyle(el).position;
+		if (position === 'fixed' || position === 'sticky') {
+			hidden.push([el, el.style.visibility]);
+			el.style.visibility = 'hidden';
+		}
+	}
+	window.__rodHiddenFixed = hidden;
+	return hidden.length;
+}`
+
+const showFixedJS = `() => {
+	for (const [el, visibility] of window.__rodHiddenFixed || []) {
+		el.style.visibility = visibility;
+	}
+	delete window.__rodHiddenFixed;
+}`
@@ -0,0 +1,86 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestStitch(t *testing.T) {
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+
+	img := stitch([]tile{
+		{img: filled(4, 10, red), y: 0},
+		{img: filled(4, 10, blue), y: 10},
+		{img: filled(4, 10, red), y: 15},
+	})
+
+	if b := img.Bounds(); b != image.Rect(0, 0, 4, 25) {
+		t.Fatalf("Expecting 4x25, got %v", b)
+	}
+
+	for y, expected := range map[int]color.RGBA{0: red, 9: red, 10: blue, 14: blue, 15: red, 24: red} {
+		if c := color.RGBAModel.Convert(img.At(1, y)).(color.RGBA); c != expected {
+			t.Errorf("Expecting %v at %d, got %v", expected, y, c)
+		}
+	}
+}
+
+func TestScreenShotTiled(t *testing.T) {
+	_, _, pt := openFixture(t, "tall.html")
+	pt.SetViewport(200, 300)
+
+	b, err := pt.ScreenShotTiled(TiledOption{HideFixed: true, LazyWait: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h := img.Bounds().Dy(); h < 1550 {
+		t.Fatalf("Expecting the whole page of 1550px or taller, got %d", h)
+	}
+
+	header := color.RGBA{R: 255, A: 255}
+	if c := color.RGBAModel.Convert(img.At(10, 10)).(color.RGBA); c != header {
+		t.Errorf("Expecting the header on the first tile, got %v", c)
+	}
+
+	if c := color.RGBAModel.Convert(img.At(10, 310)).(color.RGBA); c == header {
+		t.Error("Expecting the sticky header hidden after the first tile")
+	}
+
+	if y := pt.P.MustEval(`() => window.scrollY`).Int(); y != 0 {
+		t.Errorf("Expecting scroll restored, got %d", y)
+	}
+
+	if _, err = pt.ScreenShotTiled(TiledOption{ScreenShotOption: ScreenShotOption{Format: proto.PageCaptureScreenshotFormatWebp}}); err == nil {
+		t.Error("Expecting error for webp")
+	}
+}
+
+func TestScreenShotTiledNotScrolling(t *testing.T) {
+	_, _, pt := openFixture(t, "noscroll.html")
+	pt.SetViewport(200, 300)
+
+	b, err := pt.ScreenShotTiled(TiledOption{LazyWait: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h := img.Bounds().Dy(); h != 300 {
+		t.Errorf("Expecting one tile of the viewport for a page not scrolling, got %d", h)
+	}
+}
@@ -0,0 +1,96 @@
+package rodtemplate
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+// TracerName is the instrumentation name of spans started by templates
+const TracerName = "github.com/darimuri/go-lib/rodtemplate"
+
+// attribute keys of spans
+const (
+	AttrURL      = attribute.Key("rod.url")
+	AttrSelector = attribute.Key("rod.selector")
+	AttrFrameID  = attribute.Key("rod.frame_id")
+	AttrTarget   = attribute.Key("rod.wait.target")
+	AttrTimeout  = attribute.Key("rod.wait.timeout")
+	AttrAttempts = attribute.Key("rod.attempts")
+	AttrFound    = attribute.Key("rod.found")
+	AttrPath     = attribute.Key("rod.path")
+	AttrWorkflow = attribute.Key("rod.workflow")
+)
+
+var defaultTracerProvider trace.TracerProvider
+
+// SetDefaultTracerProvider replaces the tracer provider of templates not having one
+// and returns a func restoring the previous one.
+// The global provider of otel is used if nil, which is a no-op unless otel.SetTracerProvider is called.
+func SetDefaultTracerProvider(tp trace.TracerProvider) (restore func()) {
+	prev := defaultTracerProvider
+	defaultTracerProvider = tp
+
+	return func() {
+		defaultTracerProvider = prev
+	}
+}
+
+func tracerOf(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = defaultTracerProvider
+	}
+
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return tp.Tracer(TracerName)
+}
+
+// startSpan starts a span having ctx as parent, string attributes are redacted
+func startSpan(tp trace.TracerProvider, ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for i, attr := range attrs {
+		if attr.Value.Type() == attribute.STRING {
+			attrs[i] = attr.Key.String(credential.Redact(attr.Value.AsString()))
+		}
+	}
+
+	return tracerOf(tp).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records *errp or a panic on span and ends it, the panic is raised again.
+// It must be deferred directly to recover the panic.
+func endSpan(span trace.Span, errp *error) {
+	if val := recover(); val != nil {
+		err, ok := val.(error)
+		if !ok {
+			err = fmt.Errorf("%v", val)
+		}
+		spanError(span, err)
+		span.End()
+		panic(val)
+	}
+
+	if errp != nil && *errp != nil {
+		spanError(span, *errp)
+	}
+
+	span.End()
+}
+
+func spanError(span trace.Span, err error) {
+	err = credential.RedactError(err)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,114 @@
+package rodtemplate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/darimuri/go-lib/credential"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	sr := tracetest.NewSpanRecorder()
+	return sr, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestWaitForSpan(t *testing.T) {
+	sr, tp := newRecorder()
+	defer SetDefaultTracerProvider(tp)()
+
+	count := 0
+	if err := WaitFor("ready", time.Second, time.Millisecond, func() bool {
+		count++
+		return count == 3
+	}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WaitFor("never", 5*time.Millisecond, time.Millisecond, func() bool { return false }, func() {}); err == nil {
+		t.Fatal("Expecting timeout")
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expecting 2 spans, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "rodtemplate.WaitFor" || spanAttr(spans[0], AttrTarget).AsString() != "ready" || spanAttr(spans[0], AttrAttempts).AsInt64() != 3 {
+		t.Errorf("Expecting WaitFor span of 3 attempts, got %s %v", spans[0].Name(), spans[0].Attributes())
+	}
+
+	if spans[0].Status().Code == codes.Error || spans[1].Status().Code != codes.Error {
+		t.Errorf("Expecting error status only for timeout, got %v, %v", spans[0].Status(), spans[1].Status())
+	}
+}
+
+func TestEndSpan(t *testing.T) {
+	sr, tp := newRecorder()
+
+	credential.Secrets.Add("span-secret")
+	defer credential.Secrets.Remove("span-secret")
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expecting panic raised again")
+			}
+		}()
+
+		_, span := startSpan(tp, nil, "panic", AttrURL.String("http://localhost/?p=span-secret"))
+		defer endSpan(span, nil)
+
+		panic(errors.New("failed with span-secret"))
+	}()
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expecting ended span, got %d", len(spans))
+	}
+
+	if spans[0].Status().Description != "failed with [REDACTED]" {
+		t.Errorf("Expecting redacted error status, got %v", spans[0].Status())
+	}
+
+	if url := spanAttr(spans[0], AttrURL).AsString(); url != "http://localhost/?p=[REDACTED]" {
+		t.Errorf("Expecting redacted url, got %s", url)
+	}
+}
+
+func TestNavigateSpan(t *testing.T) {
+	s, _, pt := openFixture(t, "gate.html")
+
+	sr, tp := newRecorder()
+	pt.TracerProvider = tp
+
+	if err := pt.Navigate(s.URLOf("login.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	pt.WaitUntilHas("#id", time.Millisecond, time.Second)
+
+	spans := sr.Ended()
+	if len(spans) != 2 || spans[0].Name() != "rodtemplate.Navigate" || spanAttr(spans[0], AttrURL).AsString() != s.URLOf("login.html") {
+		t.Fatalf("Expecting Navigate span, got %d spans", len(spans))
+	}
+
+	if !spanAttr(spans[1], AttrFound).AsBool() {
+		t.Errorf("Expecting WaitUntilHas found, got %v", spans[1].Attributes())
+	}
+}
@@ -1,8 +1,8 @@
 package rodtemplate
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"net/url"
 	"reflect"
 	"strings"
@@ -12,10 +12,17 @@ import (
 	"github.com/go-rod/rod"
 )
 
+// TimeoutError is returned when a retry policy or its context stops waiting for a condition
 type TimeoutError struct {
 	Timout  time.Duration
 	Started time.Time
 	Message string
+
+	Target   string
+	Attempts int
+	Elapsed  time.Duration
+	// Err is the error of the context if it stopped waiting
+	Err error
 }
 
 func (e TimeoutError) Error() string {
@@ -26,34 +33,48 @@ func (e TimeoutError) Timeout() bool {
 	return true
 }
 
+func (e TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// isTimeout tells err is a *TimeoutError
+func isTimeout(err error) bool {
+	var te *TimeoutError
+	return errors.As(err, &te)
+}
+
+// timeoutPolicy checks once without waiting if timeout is not positive
+func timeoutPolicy(interval, timeout time.Duration) RetryPolicy {
+	policy := RetryPolicy{Backoff: ConstantBackoff(interval), Timeout: timeout}
+	if timeout <= 0 {
+		policy.MaxAttempts = 1
+	}
+
+	return policy
+}
+
+// WaitFor checks checkFunc every retryDuration calling retryFunc before each retry until timeout
 func WaitFor(targetName string, timeout, retryDuration time.Duration, checkFunc func() bool, retryFunc func()) error {
-	started := time.Now()
-	lastRetry := time.Now()
+	return waitFor(nil, targetName, timeout, retryDuration, checkFunc, retryFunc)
+}
 
-	for {
-		if checkFunc() {
-			return nil
-		}
+// waitFor logs, traces and measures with p, the defaults if p is nil
+func waitFor(p *PageTemplate, targetName string, timeout, retryDuration time.Duration, checkFunc func() bool, retryFunc func()) error {
+	policy := timeoutPolicy(retryDuration, timeout)
 
-		elapsed := time.Since(started)
-		if timeout < elapsed {
-			break
-		} else {
-			sleepDuration := retryDuration - time.Since(lastRetry)
-			log.Println("retry after sleep", sleepDuration, "for retryDuration", retryDuration, "waiting for", targetName)
-			if sleepDuration < 0 {
-				sleepDuration = retryDuration
-			}
-			time.Sleep(sleepDuration)
+	first := true
+	condition := func() (bool, error) {
+		if !first {
 			retryFunc()
-			lastRetry = time.Now()
 		}
+		first = false
+
+		return checkFunc(), nil
 	}
 
-	message := fmt.Sprintf("timeout %s exceeded after %s waiting for %s", timeout, started, targetName)
-	log.Println(message)
+	_, err := p.retry(p.context(), WaitKindWaitFor, targetName, policy, condition, nil)
 
-	return &TimeoutError{Timout: timeout, Started: started, Message: message}
+	return err
 }
 
 var errNotFound = &rod.ErrObjectNotFound{}
@@ -93,4 +114,21 @@ func IsSameDomainUrl(url1, url2 string) (bool, error) {
 	host2Domain := strings.Join(host2Split[len(host2Split)-2:], ".")
 
 	return host1Domain == host2Domain, nil
-}
\ No newline at end of file
+}
+
+// catch runs fn and turns a panic from Must* calls into an error
+func catch(fn func()) (err error) {
+	defer func() {
+		if val := recover(); val != nil {
+			if e, ok := val.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", val)
+			}
+		}
+	}()
+
+	fn()
+
+	return nil
+}
@@ -0,0 +1,344 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // baselines may be jpeg
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DefaultThreshold is the threshold of CompareOption if not given
+const DefaultThreshold = 0.1
+
+// maxYIQDelta is the delta between black and white
+const maxYIQDelta = 35215.0
+
+// CompareOption tells how CompareImages finds changed pixels
+type CompareOption struct {
+	// Threshold is the perceived color difference of a changed pixel from 0 to 1, DefaultThreshold if 0.
+	// Give a tiny value like 0.001 for an exact comparison.
+	Threshold float64
+	// Tolerance is the ratio of changed pixels from 0 to 1 which is not taken as a change
+	Tolerance float64
+	// Ignore are regions not compared, in the coordinates of the images
+	Ignore []image.Rectangle
+}
+
+// DiffResult is the result of comparing a screenshot with its baseline
+type DiffResult struct {
+	// Width and Height cover both images
+	Width, Height int
+	// Pixels is the number of changed pixels
+	Pixels int
+	// Ratio is Pixels over compared pixels
+	Ratio float64
+	// Bounds contains every changed pixel, empty if none
+	Bounds image.Rectangle
+	// SizeChanged tells the images have different sizes, pixels out of one of them are changed
+	SizeChanged bool
+	// NewBaseline tells there was no baseline and the screenshot is stored as one
+	NewBaseline bool
+	// Diff is the actual image faded in gray with changed pixels in red
+	Diff image.Image
+	// Tolerance is the one of the option compared
+	Tolerance float64
+}
+
+// Changed tells the screenshot is different from its baseline beyond the tolerance
+func (r *DiffResult) Changed() bool {
+	return r.SizeChanged || r.Ratio > r.Tolerance
+}
+
+// CompareImages compares actual with baseline pixel by pixel by perceived color difference in YIQ space
+func CompareImages(baseline, actual image.Image, opt CompareOption) *DiffResult {
+	threshold := opt.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	maxDelta := maxYIQDelta * threshold * threshold
+
+	bb, ab := baseline.Bounds(), actual.Bounds()
+	width, height := maxInt(bb.Dx(), ab.Dx()), maxInt(bb.Dy(), ab.Dy())
+
+	result := &DiffResult{
+		Width:       width,
+		Height:      height,
+		SizeChanged: bb.Dx() != ab.Dx() || bb.Dy() != ab.Dy(),
+		Tolerance:   opt.Tolerance,
+	}
+
+	diff := image.NewRGBA(image.Rect(0, 0, width, height))
+	compared := 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pt := image.Pt(x, y)
+			inBaseline, inActual := pt.In(image.Rect(0, 0, bb.Dx(), bb.Dy())), pt.In(image.Rect(0, 0, ab.Dx(), ab.Dy()))
+
+			var c color.Color = color.White
+			if inActual {
+				c = actual.At(ab.Min.X+x, ab.Min.Y+y)
+			}
+
+			if ignored(opt.Ignore, pt) {
+				diff.Set(x, y, fade(c, 0.05))
+				continue
+			}
+
+			compared++
+
+			changed := inBaseline != inActual
+			if inBaseline && inActual {
+				changed = yiqDelta(baseline.At(bb.Min.X+x, bb.Min.Y+y), c) > maxDelta
+			}
+
+			if !changed {
+				diff.Set(x, y, fade(c, 0.1))
+				continue
+			}
+
+			diff.Set(x, y, color.RGBA{R: 255, A: 255})
+			result.Pixels++
+			result.Bounds = result.Bounds.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+
+	if compared > 0 {
+		result.Ratio = float64(result.Pixels) / float64(compared)
+	}
+	result.Diff = diff
+
+	return result
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func ignored(regions []image.Rectangle, pt image.Point) bool {
+	for _, r := range regions {
+		if pt.In(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rgbOnWhite returns 8 bit components of c blended on white
+func rgbOnWhite(c color.Color) (r, g, b float64) {
+	r16, g16, b16, a16 := c.RGBA()
+	white := float64(0xffff - a16)
+
+	return (float64(r16) + white) / 257, (float64(g16) + white) / 257, (float64(b16) + white) / 257
+}
+
+// yiqDelta is the squared perceived difference of colors from "Measuring perceived color difference using YIQ NTSC
+// transmission color space in mobile applications" by Y. Kotsarenko and F. Ramos
+func yiqDelta(c1, c2 color.Color) float64 {
+	r1, g1, b1 := rgbOnWhite(c1)
+	r2, g2, b2 := rgbOnWhite(c2)
+
+	y := (r1-r2)*0.29889531 + (g1-g2)*0.58662247 + (b1-b2)*0.11448223
+	i := (r1-r2)*0.59597799 - (g1-g2)*0.27417610 - (b1-b2)*0.32180189
+	q := (r1-r2)*0.21147017 - (g1-g2)*0.52261711 + (b1-b2)*0.31114694
+
+	return 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+}
+
+// fade returns the gray of c blended on white keeping alpha of it
+func fade(c color.Color, alpha float64) color.Color {
+	r, g, b := rgbOnWhite(c)
+	gray := r*0.29889531 + g*0.58662247 + b*0.11448223
+	v := 255 + (gray-255)*alpha
+
+	return color.Gray{Y: uint8(math.Round(v))}
+}
+
+// ReadImage decodes a png or jpeg file
+func ReadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return img, nil
+}
+
+// WriteImage encodes img as png into path
+func WriteImage(path string, img image.Image) error {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// VisualOption tells how CompareScreenShot captures and compares a screenshot
+type VisualOption struct {
+	CompareOption
+	// IgnoreSelectors are elements hidden before capture and not compared, like ads or clocks
+	IgnoreSelectors []string
+	// Full captures the whole page with the viewport resized to the content when no element is given
+	Full bool
+	// DiffPath is written with the diff image as png when changed, nothing is written if empty
+	DiffPath string
+	// Update replaces the baseline with the screenshot after comparison
+	Update bool
+}
+
+// CompareScreenShot captures el, the page if nil, as png and compares it with the baseline at baselinePath.
+// The screenshot is stored as the baseline if there is none, and DiffResult.NewBaseline is set.
+func (p *PageTemplate) CompareScreenShot(el *ElementTemplate, baselinePath string, opt VisualOption) (result *DiffResult, err error) {
+	span := p.startSpan("rodtemplate.CompareScreenShot", AttrPath.String(baselinePath))
+	defer endSpan(span, &err)
+
+	page := el == nil
+	if page {
+		el = p.El("html")
+	}
+
+	var shot []byte
+	var ignore []image.Rectangle
+
+	capture := func() error {
+		restore, err := p.hide(opt.IgnoreSelectors)
+		if err != nil {
+			return err
+		}
+		defer restore()
+
+		if shot, err = p.captureElement(el, ScreenShotOption{Format: proto.PageCaptureScreenshotFormatPng}); err != nil {
+			return err
+		}
+
+		ignore, err = p.boxesIn(el, opt.IgnoreSelectors)
+		return err
+	}
+
+	if opt.Full && page {
+		err = p.fullViewport(capture)
+	} else {
+		err = capture()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := ReadImage(baselinePath)
+	if errors.Is(err, os.ErrNotExist) {
+		p.logger().Info("baseline is created", "path", baselinePath)
+		bounds := actual.Bounds()
+		return &DiffResult{Width: bounds.Dx(), Height: bounds.Dy(), NewBaseline: true, Tolerance: opt.Tolerance}, os.WriteFile(baselinePath, shot, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compareOpt := opt.CompareOption
+	compareOpt.Ignore = append(append([]image.Rectangle{}, compareOpt.Ignore...), ignore...)
+
+	result = CompareImages(baseline, actual, compareOpt)
+
+	p.logger().Debug("screenshot compared", "path", baselinePath, "changed", result.Changed(), "pixels", result.Pixels, "ratio", result.Ratio)
+
+	if result.Changed() && opt.DiffPath != "" {
+		if err = WriteImage(opt.DiffPath, result.Diff); err != nil {
+			return result, err
+		}
+	}
+
+	if opt.Update {
+		if err = os.WriteFile(baselinePath, shot, 0644); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// hide makes elements of selectors invisible keeping the layout until restore is called
+func (p *PageTemplate) hide(selectors []string) (restore func(), err error) {
+	if len(selectors) == 0 {
+		return func() {}, nil
+	}
+
+	css := strings.Join(selectors, ", ") + " { visibility: hidden !important; }"
+
+	style, err := p.P.Eval(`(css) => {
+		const style = document.createElement('style');
+		style.textContent = css;
+		document.head.appendChild(style);
+		return style;
+	}`, css)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if _, errRemove := p.P.Evaluate(rod.Eval(`function() { this.remove() }`).This(style)); errRemove != nil {
+			p.logger().Warn("failed to restore hidden elements", "error", errRemove)
+		}
+	}, nil
+}
+
+// boxesIn returns boxes of elements of selectors relative to the box of el
+func (p *PageTemplate) boxesIn(el *ElementTemplate, selectors []string) ([]image.Rectangle, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	shape, err := el.Shape()
+	if err != nil {
+		return nil, err
+	}
+	origin := shape.Box()
+
+	boxes := make([]image.Rectangle, 0)
+	for _, selector := range selectors {
+		els, err := p.P.Elements(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range els {
+			s, err := e.Shape()
+			if err != nil || len(s.Quads) == 0 {
+				continue
+			}
+
+			box := s.Box()
+			boxes = append(boxes, image.Rect(
+				int(math.Floor(box.X-origin.X)), int(math.Floor(box.Y-origin.Y)),
+				int(math.Ceil(box.X-origin.X+box.Width)), int(math.Ceil(box.Y-origin.Y+box.Height)),
+			))
+		}
+	}
+
+	return boxes, nil
+}
@@ -0,0 +1,85 @@
+package rodtemplate
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func filled(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestCompareImages(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	baseline := filled(10, 10, white)
+
+	actual := filled(10, 10, white)
+	actual.Set(2, 3, color.RGBA{A: 255})
+	actual.Set(4, 5, color.RGBA{R: 250, G: 250, B: 250, A: 255})
+
+	result := CompareImages(baseline, actual, CompareOption{})
+	if result.Pixels != 1 || result.Bounds != image.Rect(2, 3, 3, 4) || !result.Changed() {
+		t.Errorf("Expecting 1 changed pixel at (2,3) ignoring a slight one, got %d at %v", result.Pixels, result.Bounds)
+	}
+
+	if c := color.RGBAModel.Convert(result.Diff.At(2, 3)).(color.RGBA); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Expecting a red pixel in diff, got %v", c)
+	}
+
+	if result = CompareImages(baseline, actual, CompareOption{Threshold: 0.001}); result.Pixels != 2 {
+		t.Errorf("Expecting 2 changed pixels for exact comparison, got %d", result.Pixels)
+	}
+
+	if result = CompareImages(baseline, actual, CompareOption{Tolerance: 0.02}); result.Changed() {
+		t.Errorf("Expecting 1%% changed within tolerance, got %v", result.Ratio)
+	}
+
+	if result = CompareImages(baseline, actual, CompareOption{Ignore: []image.Rectangle{image.Rect(0, 0, 5, 5)}}); result.Pixels != 0 || result.Changed() {
+		t.Errorf("Expecting no change out of ignored region, got %d", result.Pixels)
+	}
+
+	result = CompareImages(baseline, filled(10, 12, white), CompareOption{Tolerance: 1})
+	if !result.SizeChanged || result.Pixels != 20 || result.Height != 12 || !result.Changed() {
+		t.Errorf("Expecting size changed with 20 pixels, got %+v", result)
+	}
+}
+
+func TestCompareScreenShot(t *testing.T) {
+	_, _, pt := openFixture(t, "visual.html")
+
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.png")
+	opt := VisualOption{IgnoreSelectors: []string{"#clock"}, DiffPath: filepath.Join(dir, "diff.png")}
+
+	result, err := pt.CompareScreenShot(nil, baselinePath, opt)
+	if err != nil || !result.NewBaseline {
+		t.Fatalf("Expecting new baseline, got %+v, %v", result, err)
+	}
+
+	pt.Reload()
+	pt.WaitLoad()
+
+	if result, err = pt.CompareScreenShot(nil, baselinePath, opt); err != nil || result.Changed() {
+		t.Fatalf("Expecting no change ignoring the clock, got %+v, %v", result, err)
+	}
+
+	pt.P.MustEval(`() => document.getElementById('banner').style.background = '#cc3333'`)
+
+	if result, err = pt.CompareScreenShot(nil, baselinePath, opt); err != nil || !result.Changed() || result.Bounds.Dx() != 300 {
+		t.Fatalf("Expecting the banner changed, got %+v, %v", result, err)
+	}
+
+	if _, err = os.Stat(opt.DiffPath); err != nil {
+		t.Errorf("Expecting diff written, got %v", err)
+	}
+}
@@ -0,0 +1,702 @@
+package rodtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow is a sequence of steps run by PageTemplate.RunWorkflow, read from YAML or JSON by ParseWorkflow.
+//
+//	name: products
+//	vars:
+//	  query: shoes
+//	steps:
+//	  - navigate: https://shop.example.com/search?q={{.query}}
+//	  - if: {exists: .cookie-banner}
+//	    then:
+//	      - click: .cookie-banner .close
+//	  - wait: {selector: .result, timeout: 10s}
+//	  - extract:
+//	      into: products
+//	      each: .result
+//	      fields:
+//	        name: css=.name
+//	        price: css=.price,strip=$,,parse=float
+//	  - each: {selector: .result a.detail, steps: [{click: ""}]}
+//	  - screenshot: {path: "{{.query}}.png", full: true}
+//	    retry: {attempts: 3, backoff: 1s}
+//
+// Strings are text/template expanded with vars, including into, each and fields of extract which are tags of Extract.
+// Retry is for steps of an action, not for if and each running nested steps.
+type Workflow struct {
+	Name  string            `yaml:"name"`
+	Vars  map[string]string `yaml:"vars"`
+	Steps []WorkflowStep    `yaml:"steps"`
+}
+
+// WorkflowStep has one action of navigate, click, input, wait, extract, screenshot, set, if or each
+type WorkflowStep struct {
+	Name string `yaml:"name"`
+
+	// Navigate is the url to open, only on pages
+	Navigate string `yaml:"navigate"`
+	// Click is the selector of the element to click once visible, empty for the element of each
+	Click *string `yaml:"click"`
+	// Input types the value into the element of selector
+	Input *InputAction `yaml:"input"`
+	// Wait waits until the conditions of the element of selector are satisfied
+	Wait *WaitAction `yaml:"wait"`
+	// Extract puts values of elements into the output
+	Extract *ExtractAction `yaml:"extract"`
+	// Screenshot writes an image of the page or the element of selector
+	Screenshot *ScreenshotAction `yaml:"screenshot"`
+	// Set expands values into vars of the following steps
+	Set map[string]string `yaml:"set"`
+	// If runs Then if satisfied, Else otherwise
+	If   *IfCondition   `yaml:"if"`
+	Then []WorkflowStep `yaml:"then"`
+	Else []WorkflowStep `yaml:"else"`
+	// Each runs steps on every element of selector
+	Each *EachAction `yaml:"each"`
+
+	// Retry runs the step again on failure, it is an error on if and each
+	Retry *RetryAction `yaml:"retry"`
+}
+
+type InputAction struct {
+	Selector string `yaml:"selector"`
+	Value    string `yaml:"value"`
+}
+
+type WaitAction struct {
+	Selector string `yaml:"selector"`
+	// Visible waits until the element is visible rather than it exists
+	Visible bool `yaml:"visible"`
+	// Hidden waits until the element is hidden or removed
+	Hidden bool `yaml:"hidden"`
+	// Text is a regular expression the text of the element matches
+	Text string `yaml:"text"`
+	// URL is a regular expression the url of the page matches, selector is not required with it
+	URL string `yaml:"url"`
+	// Timeout is 30s if 0
+	Timeout Duration `yaml:"timeout"`
+}
+
+type ExtractAction struct {
+	// Into is the key of the output
+	Into string `yaml:"into"`
+	// Each extracts a list of objects, one from every element of the selector
+	Each string `yaml:"each"`
+	// Fields are tags of Extract by name
+	Fields map[string]string `yaml:"fields"`
+	// Append appends the extracted object or list to the list of Into, like in each steps
+	Append bool `yaml:"append"`
+}
+
+type ScreenshotAction struct {
+	// Path is written as png unless it ends with .jpg, .jpeg or .webp
+	Path string `yaml:"path"`
+	// Selector is the element to capture, the viewport if empty
+	Selector string `yaml:"selector"`
+	// Full captures the whole page
+	Full bool `yaml:"full"`
+}
+
+type IfCondition struct {
+	// Exists is satisfied if the element of the selector exists
+	Exists string `yaml:"exists"`
+	// Missing is satisfied if there is no element of the selector
+	Missing string `yaml:"missing"`
+}
+
+type EachAction struct {
+	Selector string `yaml:"selector"`
+	// Index is the var having the index from 0, index if empty
+	Index string         `yaml:"index"`
+	Steps []WorkflowStep `yaml:"steps"`
+}
+
+type RetryAction struct {
+	Attempts int      `yaml:"attempts"`
+	Backoff  Duration `yaml:"backoff"`
+}
+
+// Duration reads a duration like 1m30s from YAML and JSON
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// ParseWorkflow reads a workflow from YAML or JSON, unknown keys are errors
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	w := &Workflow{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(w); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	return w, nil
+}
+
+// ReadWorkflow reads a workflow file of YAML or JSON
+func ReadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWorkflow(data)
+}
+
+// WorkflowOutput has values extracted by a workflow by Into, json.Marshal writes it as a JSON object
+type WorkflowOutput map[string]interface{}
+
+// StepError is a failure of the step at Path like steps[2].then[0]
+type StepError struct {
+	Path string
+	Name string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s: %s", e.Path, e.Err.Error())
+	}
+
+	return fmt.Sprintf("%s(%s): %s", e.Path, e.Name, e.Err.Error())
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// RunWorkflow runs steps of w on the page with vars of w overridden by vars.
+// The output has what is extracted until a step fails, the error is *StepError then.
+func (p *PageTemplate) RunWorkflow(w *Workflow, vars map[string]string) (out WorkflowOutput, err error) {
+	span := p.startSpan("rodtemplate.Workflow", AttrWorkflow.String(w.Name))
+	defer endSpan(span, &err)
+
+	merged := make(map[string]string, len(w.Vars)+len(vars))
+	for k, v := range w.Vars {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+
+	r := &workflowRun{p: p, vars: merged, out: WorkflowOutput{}}
+	err = r.runSteps(p, w.Steps, "steps")
+
+	return r.out, err
+}
+
+type workflowRun struct {
+	p    *PageTemplate
+	vars map[string]string
+	out  WorkflowOutput
+}
+
+func (r *workflowRun) runSteps(scope ElementSelector, steps []WorkflowStep, path string) error {
+	for i := range steps {
+		if err := r.runStep(scope, &steps[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *workflowRun) runStep(scope ElementSelector, s *WorkflowStep, path string) error {
+	kind, err := s.kind()
+	if err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	r.p.logger().Info("workflow step", "step", path, "name", s.Name, "action", kind)
+
+	// nested steps report their own errors
+	switch kind {
+	case "if":
+		return r.runIf(scope, s, path)
+	case "each":
+		return r.runEach(scope, s, path)
+	}
+
+	run := func() error {
+		return catch(func() {
+			if err := r.runAction(scope, s, kind); err != nil {
+				panic(err)
+			}
+		})
+	}
+
+	if s.Retry == nil {
+		err = run()
+	} else {
+		err = r.retry(s.Retry, path, run)
+	}
+
+	if err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	return nil
+}
+
+// kind returns the action of the step, an error if there is none or more than one
+func (s *WorkflowStep) kind() (string, error) {
+	kinds := make([]string, 0, 1)
+	for kind, set := range map[string]bool{
+		"navigate":   s.Navigate != "",
+		"click":      s.Click != nil,
+		"input":      s.Input != nil,
+		"wait":       s.Wait != nil,
+		"extract":    s.Extract != nil,
+		"screenshot": s.Screenshot != nil,
+		"set":        s.Set != nil,
+		"if":         s.If != nil,
+		"each":       s.Each != nil,
+	} {
+		if set {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	switch len(kinds) {
+	case 0:
+		return "", errors.New("step has no action")
+	case 1:
+		if (s.Then != nil || s.Else != nil) && kinds[0] != "if" {
+			return "", errors.New("then and else are only for if")
+		}
+		if s.Retry != nil && (kinds[0] == "if" || kinds[0] == "each") {
+			return "", fmt.Errorf("retry is not for %s, put it on the nested steps", kinds[0])
+		}
+		return kinds[0], nil
+	}
+
+	sort.Strings(kinds)
+
+	return "", fmt.Errorf("step has more than one action: %s", strings.Join(kinds, ", "))
+}
+
+// retry runs f until it succeeds following the retry of a step
+func (r *workflowRun) retry(ra *RetryAction, path string, f func() error) error {
+	policy := RetryPolicy{Backoff: ConstantBackoff(time.Duration(ra.Backoff)), MaxAttempts: ra.Attempts}
+	if ra.Backoff == 0 {
+		policy.Backoff = nil
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+
+	var last error
+	_, err := r.p.retry(r.p.context(), WaitKindWorkflowStep, path, policy, func() (bool, error) {
+		last = f()
+		if last != nil {
+			r.p.logger().Warn("workflow step failed", "step", path, "error", last)
+		}
+		return last == nil, nil
+	}, nil)
+
+	if err != nil && last != nil {
+		return fmt.Errorf("%d attempts failed: %w", policy.MaxAttempts, last)
+	}
+
+	return err
+}
+
+func (r *workflowRun) expand(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	t, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	sb := &strings.Builder{}
+	if err = t.Execute(sb, r.vars); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// expandAll expands every pointed string in order stopping at the first error
+func (r *workflowRun) expandAll(ss ...*string) error {
+	for _, s := range ss {
+		expanded, err := r.expand(*s)
+		if err != nil {
+			return err
+		}
+		*s = expanded
+	}
+
+	return nil
+}
+
+func (r *workflowRun) runAction(scope ElementSelector, s *WorkflowStep, kind string) error {
+	switch kind {
+	case "navigate":
+		url := s.Navigate
+		if err := r.expandAll(&url); err != nil {
+			return err
+		}
+		return r.p.Navigate(url)
+	case "click":
+		selector := *s.Click
+		if err := r.expandAll(&selector); err != nil {
+			return err
+		}
+		return r.click(scope, selector)
+	case "input":
+		in := *s.Input
+		if err := r.expandAll(&in.Selector, &in.Value); err != nil {
+			return err
+		}
+		return r.input(scope, in)
+	case "wait":
+		wait := *s.Wait
+		if err := r.expandAll(&wait.Selector, &wait.Text, &wait.URL); err != nil {
+			return err
+		}
+		return r.wait(scope, wait)
+	case "extract":
+		ea := *s.Extract
+		if err := r.expandAll(&ea.Into, &ea.Each); err != nil {
+			return err
+		}
+		ea.Fields = make(map[string]string, len(s.Extract.Fields))
+		for name, tag := range s.Extract.Fields {
+			expanded, err := r.expand(tag)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+			ea.Fields[name] = expanded
+		}
+		return r.extract(scope, &ea)
+	case "screenshot":
+		shot := *s.Screenshot
+		if err := r.expandAll(&shot.Path, &shot.Selector); err != nil {
+			return err
+		}
+		return r.screenshot(scope, shot)
+	case "set":
+		for _, k := range sortedKeys(s.Set) {
+			v, err := r.expand(s.Set[k])
+			if err != nil {
+				return err
+			}
+			r.vars[k] = v
+		}
+	}
+
+	return nil
+}
+
+// target returns the element of selector under scope, the element of scope itself if selector is empty
+func target(scope ElementSelector, selector string) (*ElementTemplate, error) {
+	if selector == "" {
+		if el, ok := scope.(*ElementTemplate); ok {
+			return el, nil
+		}
+		return nil, errors.New("selector is required on a page")
+	}
+
+	if !scope.Has(selector) {
+		return nil, fmt.Errorf("element of %s is not found", selector)
+	}
+
+	return scope.El(selector), nil
+}
+
+func (r *workflowRun) click(scope ElementSelector, selector string) error {
+	if scope == ElementSelector(r.p) {
+		if !r.p.ClickWhenAvailable(selector) {
+			return fmt.Errorf("element of %s is not available", selector)
+		}
+		return nil
+	}
+
+	el, err := target(scope, selector)
+	if err != nil {
+		return err
+	}
+
+	el.MustScrollIntoView()
+
+	return el.Click(proto.InputMouseButtonLeft, 1)
+}
+
+func (r *workflowRun) input(scope ElementSelector, in InputAction) error {
+	if scope == ElementSelector(r.p) {
+		r.p.Input(in.Selector, in.Value)
+		return nil
+	}
+
+	el, err := target(scope, in.Selector)
+	if err != nil {
+		return err
+	}
+
+	el.MustClick().MustSelectAllText().MustInput(in.Value)
+
+	return nil
+}
+
+func (r *workflowRun) wait(scope ElementSelector, wait WaitAction) error {
+	timeout := time.Duration(wait.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	c := r.p.Cond()
+	if el, ok := scope.(*ElementTemplate); ok {
+		c = el.Cond()
+	}
+
+	conditions := make([]Condition, 0, 3)
+	if wait.URL != "" {
+		re, err := regexp.Compile(wait.URL)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, c.URLMatches(re))
+	}
+
+	if wait.Selector != "" {
+		switch {
+		case wait.Hidden:
+			conditions = append(conditions, c.Hidden(wait.Selector))
+		case wait.Visible:
+			conditions = append(conditions, c.Visible(wait.Selector))
+		default:
+			conditions = append(conditions, c.Exists(wait.Selector))
+		}
+
+		if wait.Text != "" {
+			re, err := regexp.Compile(wait.Text)
+			if err != nil {
+				return err
+			}
+			conditions = append(conditions, c.TextMatches(wait.Selector, re))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return errors.New("wait requires selector or url")
+	}
+
+	return r.p.WaitUntil(wait.Selector+wait.URL, timeout, AllOf(conditions...))
+}
+
+func (r *workflowRun) extract(scope ElementSelector, ea *ExtractAction) error {
+	if ea.Into == "" {
+		return errors.New("extract requires into")
+	}
+
+	var value interface{}
+	if ea.Each == "" {
+		object, err := extractFields(scope, ea.Fields)
+		if err != nil {
+			return err
+		}
+		value = object
+	} else {
+		list := make([]interface{}, 0)
+		if scope.Has(ea.Each) {
+			for _, el := range scope.Els(ea.Each) {
+				object, err := extractFields(el, ea.Fields)
+				if err != nil {
+					return err
+				}
+				list = append(list, object)
+			}
+		}
+		value = list
+	}
+
+	if !ea.Append {
+		r.out[ea.Into] = value
+		return nil
+	}
+
+	prev, _ := r.out[ea.Into].([]interface{})
+	if list, ok := value.([]interface{}); ok {
+		r.out[ea.Into] = append(prev, list...)
+	} else {
+		r.out[ea.Into] = append(prev, value)
+	}
+
+	return nil
+}
+
+// extractFields reads fields, tags of Extract by name, under scope into a JSON object
+func extractFields(scope ElementSelector, fields map[string]string) (map[string]interface{}, error) {
+	x := &extractor{}
+	object := make(map[string]interface{}, len(fields))
+
+	for _, name := range sortedKeys(fields) {
+		opt, err := parseExtractTag(fields[name])
+		if err != nil {
+			x.fail(name, "", err)
+			continue
+		}
+
+		// a pointer is left nil for a missing optional element
+		fv := reflect.New(reflect.PtrTo(workflowValueType(opt.parse))).Elem()
+		x.fillField(scope, fv, name, opt)
+
+		object[name] = nil
+		if !fv.IsNil() {
+			object[name] = fv.Elem().Interface()
+		}
+	}
+
+	if len(x.errors) > 0 {
+		return object, &ExtractError{Fields: x.errors}
+	}
+
+	return object, nil
+}
+
+// workflowValueType is the type extracted by parse of extract fields
+func workflowValueType(parse string) reflect.Type {
+	switch parse {
+	case "int":
+		return reflect.TypeOf(int64(0))
+	case "uint":
+		return reflect.TypeOf(uint64(0))
+	case "float":
+		return reflect.TypeOf(float64(0))
+	case "bool":
+		return reflect.TypeOf(false)
+	}
+
+	return reflect.TypeOf("")
+}
+
+func (r *workflowRun) screenshot(scope ElementSelector, shot ScreenshotAction) error {
+	if shot.Path == "" {
+		return errors.New("screenshot requires path")
+	}
+
+	opt := ScreenShotOption{Format: ScreenShotFormatOf(shot.Path)}
+
+	var b []byte
+	var err error
+
+	switch {
+	case shot.Full:
+		b, err = r.p.ScreenShotFullBytes(opt)
+	case shot.Selector == "" && scope == ElementSelector(r.p):
+		b, err = r.p.ScreenShotBytes(nil, opt)
+	default:
+		var el *ElementTemplate
+		if el, err = target(scope, shot.Selector); err == nil {
+			b, err = r.p.ScreenShotBytes(el, opt)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(shot.Path, b, 0644)
+}
+
+func (r *workflowRun) runIf(scope ElementSelector, s *WorkflowStep, path string) error {
+	exists, missing := s.If.Exists, s.If.Missing
+	if err := r.expandAll(&exists, &missing); err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	if (exists == "") == (missing == "") {
+		return &StepError{Path: path, Name: s.Name, Err: errors.New("if requires one of exists and missing")}
+	}
+
+	var satisfied bool
+	if err := catch(func() {
+		if exists != "" {
+			satisfied = scope.Has(exists)
+		} else {
+			satisfied = !scope.Has(missing)
+		}
+	}); err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	if satisfied {
+		return r.runSteps(scope, s.Then, path+".then")
+	}
+
+	return r.runSteps(scope, s.Else, path+".else")
+}
+
+func (r *workflowRun) runEach(scope ElementSelector, s *WorkflowStep, path string) error {
+	selector := s.Each.Selector
+	if err := r.expandAll(&selector); err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	index := s.Each.Index
+	if index == "" {
+		index = "index"
+	}
+
+	var els ElementsTemplate
+	if err := catch(func() {
+		if scope.Has(selector) {
+			els = scope.Els(selector)
+		}
+	}); err != nil {
+		return &StepError{Path: path, Name: s.Name, Err: err}
+	}
+
+	for i, el := range els {
+		r.vars[index] = fmt.Sprint(i)
+		if err := r.runSteps(el, s.Each.Steps, fmt.Sprintf("%s.each[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// JSON returns the output as an indented JSON object
+func (o WorkflowOutput) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}(o), "", "  ")
+}
@@ -0,0 +1,143 @@
+package rodtemplate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testWorkflow = `
+name: products
+vars:
+  product: product
+steps:
+  - name: open
+    navigate: "{{.url}}"
+  - if: {missing: .cookie-banner}
+    then:
+      - set: {grid: "#grid"}
+    else:
+      - click: .cookie-banner .close
+  - wait: {selector: "#{{.product}} .name", text: "^Apple$", timeout: 5s}
+  - extract:
+      into: product
+      fields:
+        name: css=#{{.product}} .name
+        price: css=#product .price,strip=,,parse=int
+        link: css=#product .link,attr=href
+        discount: css=#product .discount,optional
+  - extract:
+      into: rows
+      each: "{{.grid}} .row:not(.head)"
+      fields:
+        product: css=.cell:nth-child(1)
+        price: css=.cell:nth-child(2),strip=,,parse=float
+  - each:
+      selector: "#product .tags li"
+      steps:
+        - extract: {into: tags, append: true, fields: {tag: "", index: "css=.missing,optional"}}
+  - screenshot: {path: "{{.dir}}/product.png", selector: "#product"}
+    retry: {attempts: 2, backoff: 10ms}
+`
+
+func TestParseWorkflow(t *testing.T) {
+	w, err := ParseWorkflow([]byte(testWorkflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Name != "products" || len(w.Steps) != 7 || w.Vars["product"] != "product" {
+		t.Fatalf("Expecting 7 steps of products, got %+v", w)
+	}
+
+	if s := w.Steps[6]; s.Retry == nil || s.Retry.Attempts != 2 || time.Duration(s.Retry.Backoff) != 10*time.Millisecond {
+		t.Errorf("Expecting retry of 2 attempts, got %+v", s.Retry)
+	}
+
+	if d := time.Duration(w.Steps[2].Wait.Timeout); d != 5*time.Second {
+		t.Errorf("Expecting 5s timeout, got %s", d)
+	}
+
+	w, err = ParseWorkflow([]byte(`{"steps": [{"click": ".next", "retry": {"attempts": 3, "backoff": "1s"}}]}`))
+	if err != nil || len(w.Steps) != 1 || *w.Steps[0].Click != ".next" || w.Steps[0].Retry.Attempts != 3 {
+		t.Errorf("Expecting a click step from json, got %+v, %v", w, err)
+	}
+
+	for _, invalid := range []string{
+		`steps: [{clik: .next}]`,
+		`steps: [{wait: {selector: a, timeout: soon}}]`,
+	} {
+		if _, err = ParseWorkflow([]byte(invalid)); err == nil {
+			t.Errorf("Expecting error for %s", invalid)
+		}
+	}
+}
+
+func TestWorkflowStepKind(t *testing.T) {
+	next := ".next"
+
+	for _, c := range []struct {
+		step WorkflowStep
+		kind string
+	}{
+		{WorkflowStep{Click: &next}, "click"},
+		{WorkflowStep{If: &IfCondition{Exists: "a"}, Then: []WorkflowStep{}}, "if"},
+		{WorkflowStep{}, ""},
+		{WorkflowStep{Click: &next, Navigate: "https://example.com"}, ""},
+		{WorkflowStep{Click: &next, Then: []WorkflowStep{}}, ""},
+		{WorkflowStep{Click: &next, Retry: &RetryAction{Attempts: 2}}, "click"},
+		{WorkflowStep{If: &IfCondition{Exists: "a"}, Retry: &RetryAction{Attempts: 2}}, ""},
+		{WorkflowStep{Each: &EachAction{Selector: "a"}, Retry: &RetryAction{Attempts: 2}}, ""},
+	} {
+		if kind, err := c.step.kind(); kind != c.kind || (kind == "") != (err != nil) {
+			t.Errorf("Expecting %q for %+v, got %q, %v", c.kind, c.step, kind, err)
+		}
+	}
+}
+
+func TestRunWorkflow(t *testing.T) {
+	s, _, pt := openFixture(t, "gate.html")
+
+	w, err := ParseWorkflow([]byte(testWorkflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	out, err := pt.RunWorkflow(w, map[string]string{"url": s.URLOf("table.html"), "dir": dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := out.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(`{
+  "product": {"discount": null, "link": "/products/apple", "name": "Apple", "price": 1200},
+  "rows": [{"price": 1200, "product": "Apple"}, {"price": 800, "product": "Banana"}],
+  "tags": [{"index": null, "tag": "fruit"}, {"index": null, "tag": "red"}]
+}`), &expected)
+	expectedJSON, _ := json.MarshalIndent(expected, "", "  ")
+
+	if string(data) != string(expectedJSON) {
+		t.Errorf("Expecting %s, got %s", expectedJSON, data)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "product.png")); err != nil {
+		t.Errorf("Expecting screenshot written, got %v", err)
+	}
+
+	var se *StepError
+	_, err = pt.RunWorkflow(&Workflow{Steps: []WorkflowStep{{If: &IfCondition{Exists: "#product"}, Then: []WorkflowStep{
+		{Name: "missing", Input: &InputAction{Selector: "#missing", Value: "{{.undefined}}"}},
+	}}}}, nil)
+	if !errors.As(err, &se) || se.Path != "steps[0].then[0]" || se.Name != "missing" {
+		t.Errorf("Expecting StepError of steps[0].then[0], got %v", err)
+	}
+}